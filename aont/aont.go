@@ -0,0 +1,152 @@
+// Package aont implements AONT-RS information dispersal on top of a
+// reedsolomon.Encoder.
+//
+// Before the data is split into shards it is passed through an
+// all-or-nothing transform (AONT): the data is encrypted with AES-256-CTR
+// using a random key, and the key is folded into a SHA-256 hash of the
+// ciphertext. The key can only be recovered when the complete ciphertext
+// is available, so fewer than DataShards shards reveal nothing useful
+// about the content, even though the code is systematic.
+//
+// A known canary value is encrypted along with the data, which allows
+// Recover to detect corrupted or mismatched shards.
+//
+// See "AONT-RS: Blending Security and Performance in Dispersed Storage
+// Systems" by Jason K. Resch and James S. Plank.
+package aont
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+const (
+	headerSize = 8
+	keySize    = 32
+	canarySize = 16
+
+	// Overhead is the number of bytes added to the data before it is split.
+	Overhead = headerSize + canarySize + keySize
+)
+
+// canary is appended to the plaintext before encryption.
+var canary [canarySize]byte
+
+// ErrIntegrity is returned by Recover if the recovered data
+// does not pass the integrity check.
+var ErrIntegrity = errors.New("aont: integrity check failed")
+
+// ErrEmptyData is returned by Disperse if no data is given.
+var ErrEmptyData = errors.New("aont: no data")
+
+// Disperse transforms data and splits it into enc.TotalShards() shards,
+// with parity shards encoded.
+//
+// The returned package layout, before splitting, is:
+//
+//	length (8 bytes, little endian) | AES-CTR(data | canary) | key XOR SHA-256(length | ciphertext)
+//
+// The input data is not modified.
+func Disperse(enc reedsolomon.Encoder, data []byte) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	var key [keySize]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, err
+	}
+	defer clear(key[:])
+
+	pkg := make([]byte, Overhead+len(data))
+	binary.LittleEndian.PutUint64(pkg, uint64(len(data)))
+	body := pkg[headerSize : headerSize+len(data)+canarySize]
+	copy(body, data)
+	copy(body[len(data):], canary[:])
+	stream, err := newStream(key[:])
+	if err != nil {
+		return nil, err
+	}
+	stream.XORKeyStream(body, body)
+
+	h := sha256.Sum256(pkg[:headerSize+len(body)])
+	subtle.XORBytes(pkg[headerSize+len(body):], key[:], h[:])
+
+	shards, err := enc.Split(pkg)
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// Recover the data from shards produced by Disperse.
+// Missing shards should be nil or zero length.
+// At least DataShards shards must be present.
+//
+// Missing data shards are reconstructed in place.
+// If the shards do not decode to a valid package, ErrIntegrity is returned.
+func Recover(enc reedsolomon.Encoder, shards [][]byte) ([]byte, error) {
+	if err := enc.ReconstructData(shards); err != nil {
+		return nil, err
+	}
+	ext, ok := enc.(reedsolomon.Extensions)
+	if !ok {
+		return nil, reedsolomon.ErrNotSupported
+	}
+	size := 0
+	for _, shard := range shards[:ext.DataShards()] {
+		size += len(shard)
+	}
+	var buf bytes.Buffer
+	buf.Grow(size)
+	if err := enc.Join(&buf, shards, size); err != nil {
+		return nil, err
+	}
+	pkg := buf.Bytes()
+	if len(pkg) < Overhead {
+		return nil, ErrIntegrity
+	}
+	n := binary.LittleEndian.Uint64(pkg)
+	if n == 0 || n > uint64(len(pkg)-Overhead) {
+		return nil, ErrIntegrity
+	}
+	bodyEnd := headerSize + int(n) + canarySize
+	h := sha256.Sum256(pkg[:bodyEnd])
+	var key [keySize]byte
+	defer clear(key[:])
+	subtle.XORBytes(key[:], pkg[bodyEnd:bodyEnd+keySize], h[:])
+
+	body := pkg[headerSize:bodyEnd]
+	stream, err := newStream(key[:])
+	if err != nil {
+		return nil, err
+	}
+	stream.XORKeyStream(body, body)
+	if subtle.ConstantTimeCompare(body[n:], canary[:]) != 1 {
+		clear(body)
+		return nil, ErrIntegrity
+	}
+	return body[:n:n], nil
+}
+
+// newStream returns the AES-256-CTR stream for key.
+// Since every key is only used once, a zero IV is used.
+func newStream(key []byte) (cipher.Stream, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	var iv [aes.BlockSize]byte
+	return cipher.NewCTR(block, iv[:]), nil
+}
@@ -0,0 +1,101 @@
+package aont
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+func TestDisperseRecover(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, test := range []struct {
+		data, parity, size int
+		opts               []reedsolomon.Option
+	}{
+		{data: 1, parity: 1, size: 1},
+		{data: 4, parity: 2, size: 100},
+		{data: 10, parity: 4, size: 100000},
+		{data: 8, parity: 4, size: 12345, opts: []reedsolomon.Option{reedsolomon.WithLeopardGF(true)}},
+		{data: 300, parity: 20, size: 54321},
+	} {
+		enc, err := reedsolomon.New(test.data, test.parity, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := make([]byte, test.size)
+		rng.Read(data)
+		shards, err := Disperse(enc, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shards) != test.data+test.parity {
+			t.Fatalf("got %d shards, want %d", len(shards), test.data+test.parity)
+		}
+		ok, err := enc.Verify(shards)
+		if err != nil || !ok {
+			t.Fatalf("verify: %v, %v", ok, err)
+		}
+		if test.size >= 100 {
+			for i, shard := range shards {
+				if bytes.Contains(shard, data[:32]) {
+					t.Fatalf("shard %d contains plaintext", i)
+				}
+			}
+		}
+
+		// Drop as many shards as possible.
+		for _, idx := range rng.Perm(len(shards))[:test.parity] {
+			shards[idx] = nil
+		}
+		got, err := Recover(enc, shards)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("%d+%d: recovered data mismatch", test.data, test.parity)
+		}
+	}
+}
+
+func TestRecoverCorrupt(t *testing.T) {
+	enc, err := reedsolomon.New(5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(0)).Read(data)
+	shards, err := Disperse(enc, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		corrupt := make([][]byte, len(shards))
+		for j := range shards {
+			corrupt[j] = append([]byte{}, shards[j]...)
+		}
+		corrupt[i][len(corrupt[i])/2] ^= 1
+		if _, err := Recover(enc, corrupt); err != ErrIntegrity {
+			t.Errorf("shard %d: expected %v, got %v", i, ErrIntegrity, err)
+		}
+	}
+
+	// Mixing shards from two dispersals of the same data must fail.
+	other, err := Disperse(enc, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixed := append([][]byte{other[0]}, shards[1:]...)
+	if _, err := Recover(enc, mixed); err != ErrIntegrity {
+		t.Errorf("expected %v, got %v", ErrIntegrity, err)
+	}
+
+	if _, err := Disperse(enc, nil); err != ErrEmptyData {
+		t.Errorf("expected %v, got %v", ErrEmptyData, err)
+	}
+	shards[0], shards[1], shards[2], shards[3] = nil, nil, nil, nil
+	if _, err := Recover(enc, shards); err != reedsolomon.ErrTooFewShards {
+		t.Errorf("expected %v, got %v", reedsolomon.ErrTooFewShards, err)
+	}
+}
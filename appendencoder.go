@@ -0,0 +1,108 @@
+package reedsolomon
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrSealed is returned when appending to an AppendEncoder that has been sealed.
+var ErrSealed = errors.New("encoder has been sealed")
+
+// AppendEncoder keeps parity up to date while data shards grow.
+//
+// Data shards can grow by different amounts. The parity is calculated as if
+// the unwritten tail of each data shard is zero, so the parity always has
+// the length of the longest data shard.
+// Appending only calculates the parity of the new data,
+// using EncodeIdxRange.
+//
+// An AppendEncoder is not safe for concurrent use.
+type AppendEncoder struct {
+	r       *reedSolomon
+	lengths []int
+	parity  [][]byte
+	sealed  bool
+}
+
+// NewAppendEncoder creates an AppendEncoder for the given number of
+// data and parity shards.
+// Leopard GF is not supported, and ErrNotSupported is returned if
+// the options select it.
+func NewAppendEncoder(dataShards, parityShards int, opts ...Option) (*AppendEncoder, error) {
+	enc, err := New(dataShards, parityShards, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := enc.(*reedSolomon)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return &AppendEncoder{
+		r:       r,
+		lengths: make([]int, dataShards),
+		parity:  make([][]byte, parityShards),
+	}, nil
+}
+
+// Append adds data to the end of data shard idx and updates the parity.
+// The data is not retained.
+func (a *AppendEncoder) Append(idx int, data []byte) error {
+	if a.sealed {
+		return ErrSealed
+	}
+	if idx < 0 || idx >= len(a.lengths) {
+		return ErrInvShardNum
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	start := a.lengths[idx]
+	end := start + len(data)
+	if len(a.parity) > 0 {
+		size := len(a.parity[0])
+		if end > size {
+			// Grow the parity with zeros.
+			// Memory beyond the length is never written, so it is zero.
+			for i, p := range a.parity {
+				a.parity[i] = slices.Grow(p, end-len(p))[:end]
+			}
+		}
+		if err := a.r.EncodeIdxRange(data, idx, start, a.parity); err != nil {
+			// Shrink the parity back to its previous size.
+			for i, p := range a.parity {
+				a.parity[i] = p[:size]
+			}
+			return err
+		}
+	}
+	a.lengths[idx] = end
+	return nil
+}
+
+// Len returns the current length of data shard idx.
+// 0 is returned if idx is not a valid data shard index.
+func (a *AppendEncoder) Len(idx int) int {
+	if idx < 0 || idx >= len(a.lengths) {
+		return 0
+	}
+	return a.lengths[idx]
+}
+
+// Seal stops appending and returns the parity shards.
+// The parity shards have the length of the longest data shard.
+// Shorter data shards must be zero padded to this length
+// before they are verified or reconstructed with the parity.
+// Append will return ErrSealed after Seal has been called.
+func (a *AppendEncoder) Seal() [][]byte {
+	a.sealed = true
+	size := 0
+	for _, l := range a.lengths {
+		size = max(size, l)
+	}
+	for i := range a.parity {
+		if a.parity[i] == nil {
+			a.parity[i] = make([]byte, size)
+		}
+	}
+	return a.parity
+}
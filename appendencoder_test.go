@@ -0,0 +1,80 @@
+package reedsolomon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestAppendEncoder(t *testing.T) {
+	const dataShards, parityShards = 5, 3
+	a, err := NewAppendEncoder(dataShards, parityShards, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(0))
+	data := make([][]byte, dataShards)
+	for range 200 {
+		idx := rng.Intn(dataShards - 1) // The last data shard stays empty.
+		b := make([]byte, rng.Intn(2000))
+		fillRandom(b)
+		if err := a.Append(idx, b); err != nil {
+			t.Fatal(err)
+		}
+		data[idx] = append(data[idx], b...)
+		if a.Len(idx) != len(data[idx]) {
+			t.Fatalf("shard %d: expected length %d, got %d", idx, len(data[idx]), a.Len(idx))
+		}
+	}
+	parity := a.Seal()
+	if err := a.Append(0, []byte{1}); err != ErrSealed {
+		t.Errorf("expected %v, got %v", ErrSealed, err)
+	}
+
+	// Pad the data shards and verify with a regular encoder.
+	size := len(parity[0])
+	shards := make([][]byte, 0, dataShards+parityShards)
+	for _, d := range data {
+		if len(d) > size {
+			t.Fatalf("parity size %d shorter than data %d", size, len(d))
+		}
+		shards = append(shards, append(d, make([]byte, size-len(d))...))
+	}
+	shards = append(shards, parity...)
+	enc, err := New(dataShards, parityShards, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := enc.Verify(shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+	want := bytes.Clone(shards[1])
+	shards[1] = nil
+	if err := enc.Reconstruct(shards); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(shards[1], want) {
+		t.Fatal("reconstructed shard does not match")
+	}
+
+	if err := a.Append(dataShards, nil); err != ErrSealed {
+		t.Errorf("expected %v, got %v", ErrSealed, err)
+	}
+	a, _ = NewAppendEncoder(dataShards, parityShards, testOptions()...)
+	if err := a.Append(dataShards, []byte{1}); err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	if n := a.Len(dataShards); n != 0 {
+		t.Errorf("expected length 0 for invalid shard, got %d", n)
+	}
+	if n := a.Len(-1); n != 0 {
+		t.Errorf("expected length 0 for invalid shard, got %d", n)
+	}
+	if _, err := NewAppendEncoder(dataShards, parityShards, WithLeopardGF(true)); err != ErrNotSupported {
+		t.Errorf("expected %v, got %v", ErrNotSupported, err)
+	}
+}
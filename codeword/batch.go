@@ -0,0 +1,106 @@
+package codeword
+
+import (
+	"github.com/klauspost/reedsolomon"
+)
+
+// initBatch prepares a reedsolomon encoder that computes the parity
+// of many codewords at once.
+//
+// Systematic encoding is linear, so the parity of a message is the message
+// multiplied by a k x (n-k) matrix. The rows are found by encoding unit vectors.
+// If the code uses another field than the reedsolomon package, the matrix and
+// the symbols are mapped through a field isomorphism.
+func (c *Codec) initBatch() error {
+	rows := make([][]byte, c.nroots)
+	for i := range rows {
+		rows[i] = make([]byte, c.k)
+	}
+	unit := make([]byte, c.k)
+	parity := make([]byte, c.nroots)
+	for i := range unit {
+		unit[i] = 1
+		c.encode(unit, parity)
+		unit[i] = 0
+		for j, v := range parity {
+			rows[j][i] = v
+		}
+	}
+
+	if c.f.poly != defaultPoly {
+		dst, err := newField(defaultPoly)
+		if err != nil {
+			return err
+		}
+		to, from := c.f.isomorphism(dst)
+		for _, row := range rows {
+			for i, v := range row {
+				row[i] = to[v]
+			}
+		}
+		c.mapIn, c.mapOut = &to, &from
+	}
+	if c.dualBasis {
+		var in, out [256]byte
+		for i := range 256 {
+			v := c.fromDual[i]
+			if c.mapIn != nil {
+				v = c.mapIn[v]
+			}
+			in[i] = v
+			v = byte(i)
+			if c.mapOut != nil {
+				v = c.mapOut[v]
+			}
+			out[i] = c.toDual[v]
+		}
+		c.mapIn, c.mapOut = &in, &out
+	}
+
+	var err error
+	c.batch, err = reedsolomon.New(c.k, c.nroots, reedsolomon.WithCustomMatrix(rows))
+	return err
+}
+
+// EncodeBatch calculates the parity of many codewords at once.
+//
+// The codewords are given in interleaved form: symbols must contain N slices
+// of equal length, where symbols[i][j] is symbol i of codeword j.
+// The first K slices contain the messages and the
+// parity will be written to the last ParitySymbols slices.
+//
+// This is equivalent to calling Encode on each codeword,
+// but uses the SIMD accelerated kernels of the reedsolomon package.
+func (c *Codec) EncodeBatch(symbols [][]byte) error {
+	if len(symbols) != c.n {
+		return ErrInvalidSize
+	}
+	size := len(symbols[0])
+	for _, s := range symbols {
+		if len(s) != size || size == 0 {
+			return ErrInvalidSize
+		}
+	}
+	if c.mapIn == nil {
+		return c.batch.Encode(symbols)
+	}
+
+	shards := make([][]byte, c.n)
+	copy(shards[c.k:], symbols[c.k:])
+	for i, s := range symbols[:c.k] {
+		mapped := make([]byte, size)
+		for j, v := range s {
+			mapped[j] = c.mapIn[v]
+		}
+		shards[i] = mapped
+	}
+	if err := c.batch.Encode(shards); err != nil {
+		return err
+	}
+	for _, s := range symbols[c.k:] {
+		for j, v := range s {
+			s[j] = c.mapOut[v]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,484 @@
+// Package codeword implements classic byte-level Reed-Solomon codes,
+// RS(n, k) over GF(2^8), with error and erasure correction.
+//
+// Unlike the shard oriented reedsolomon package, each codeword is
+// a message of k bytes followed by n-k parity bytes, and the decoder can
+// locate and correct up to (n-k)/2 errors at unknown positions
+// using the Berlekamp-Massey algorithm, Chien search and Forney's formula.
+// Erasures (errors at known positions) count half as much,
+// so 2*errors + erasures <= n-k can be corrected.
+//
+// The field polynomial, first consecutive root and the generator step
+// (the primitive element used for the roots of the generator polynomial)
+// are configurable, which covers common codes such as the ones used in
+// QR codes and the CCSDS RS(255,223) code including its dual basis
+// representation. Codes with n < 255 are shortened codes.
+//
+// Many codewords can be encoded at once with EncodeBatch, which uses the
+// SIMD kernels of the reedsolomon package.
+package codeword
+
+import (
+	"errors"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// ErrInvalidParams is returned by New if the code parameters are invalid.
+var ErrInvalidParams = errors.New("codeword: invalid code parameters")
+
+// ErrInvalidSize is returned if the input has an unexpected length.
+var ErrInvalidSize = errors.New("codeword: invalid size")
+
+// ErrInvalidErasure is returned by Decode if an erasure position is out of range
+// or there are more erasures than parity symbols.
+var ErrInvalidErasure = errors.New("codeword: invalid erasure")
+
+// ErrTooManyErrors is returned by Decode if the codeword cannot be corrected.
+var ErrTooManyErrors = errors.New("codeword: too many errors")
+
+// Option allows to override code parameters.
+type Option func(*options)
+
+type options struct {
+	poly      int
+	fcr       int
+	prim      int
+	dualBasis bool
+}
+
+var defaultOptions = options{
+	poly: defaultPoly,
+	fcr:  0,
+	prim: 1,
+}
+
+// WithFieldPolynomial sets the primitive polynomial generating the field,
+// including the x^8 term, for example 0x11d (the default) or 0x187.
+func WithFieldPolynomial(poly int) Option {
+	return func(o *options) {
+		o.poly = poly
+	}
+}
+
+// WithFirstRoot sets the first consecutive root of the generator polynomial,
+// as a power of the generator element. Default is 0.
+func WithFirstRoot(fcr int) Option {
+	return func(o *options) {
+		o.fcr = fcr
+	}
+}
+
+// WithGenerator sets the generator element of the roots as a power of the
+// primitive element alpha of the field. The generator polynomial is
+//
+//	g(x) = (x - alpha^(prim*fcr)) * (x - alpha^(prim*(fcr+1))) * ... * (x - alpha^(prim*(fcr+n-k-1)))
+//
+// prim must be relatively prime to 255. Default is 1.
+func WithGenerator(prim int) Option {
+	return func(o *options) {
+		o.prim = prim
+	}
+}
+
+// WithDualBasis will make all symbols, both input and output,
+// use the CCSDS dual basis representation.
+func WithDualBasis(enabled bool) Option {
+	return func(o *options) {
+		o.dualBasis = enabled
+	}
+}
+
+// WithCCSDS sets the parameters of the CCSDS code as given in CCSDS 131.0-B:
+// field polynomial 0x187, first root 112, generator alpha^11 and
+// dual basis representation. Use with New(255, 223) or a shortened version.
+func WithCCSDS() Option {
+	return func(o *options) {
+		o.poly = 0x187
+		o.fcr = 112
+		o.prim = 11
+		o.dualBasis = true
+	}
+}
+
+// Codec encodes and decodes codewords for a specific code.
+// A Codec is safe for concurrent use.
+type Codec struct {
+	n, k   int
+	nroots int
+	pad    int // Number of symbols the code is shortened by.
+	fcr    int
+	prim   int
+	iprim  int // prim-th root of 1, index form.
+
+	f       *field
+	genPoly []byte // Generator polynomial, index form.
+
+	dualBasis        bool
+	toDual, fromDual [256]byte
+
+	// Batch encoding.
+	batch  reedsolomon.Encoder
+	mapIn  *[256]byte // Maps input symbols to the batch field, if needed.
+	mapOut *[256]byte // Maps parity from the batch field, if needed.
+}
+
+// New creates a codec for codewords of n symbols, of which k are message symbols.
+// n must be at most 255, and n-k parity symbols are added to each message.
+// If n is less than 255 the code is shortened.
+func New(n, k int, opts ...Option) (*Codec, error) {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if n > nn || k <= 0 || k >= n {
+		return nil, ErrInvalidParams
+	}
+	if o.fcr < 0 || o.fcr >= nn || o.prim <= 0 || o.prim >= nn || gcd(o.prim, nn) != 1 {
+		return nil, ErrInvalidParams
+	}
+	f, err := newField(o.poly)
+	if err != nil {
+		return nil, err
+	}
+	c := Codec{
+		n:         n,
+		k:         k,
+		nroots:    n - k,
+		pad:       nn - n,
+		fcr:       o.fcr,
+		prim:      o.prim,
+		f:         f,
+		dualBasis: o.dualBasis,
+	}
+	// Find prim-th root of 1, used in the Chien search.
+	iprim := 1
+	for iprim%o.prim != 0 {
+		iprim += nn
+	}
+	c.iprim = iprim / o.prim
+
+	// Form the generator polynomial from its roots.
+	gen := make([]byte, c.nroots+1)
+	gen[0] = 1
+	root := o.fcr * o.prim
+	for i := 0; i < c.nroots; i++ {
+		gen[i+1] = 1
+		// Multiply gen by (x + alpha^root)
+		for j := i; j > 0; j-- {
+			if gen[j] != 0 {
+				gen[j] = gen[j-1] ^ f.alphaTo[modnn(int(f.indexOf[gen[j]])+root)]
+			} else {
+				gen[j] = gen[j-1]
+			}
+		}
+		// gen[0] can never be zero.
+		gen[0] = f.alphaTo[modnn(int(f.indexOf[gen[0]])+root)]
+		root += o.prim
+	}
+	// Convert to index form for quicker encoding.
+	for i := range gen {
+		gen[i] = f.indexOf[gen[i]]
+	}
+	c.genPoly = gen
+
+	if c.dualBasis {
+		c.toDual, c.fromDual = dualBasisTables()
+	}
+	if err := c.initBatch(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// N returns the number of symbols in a codeword.
+func (c *Codec) N() int { return c.n }
+
+// K returns the number of message symbols in a codeword.
+func (c *Codec) K() int { return c.k }
+
+// ParitySymbols returns the number of parity symbols in a codeword.
+func (c *Codec) ParitySymbols() int { return c.nroots }
+
+// Encode calculates the parity of a message.
+// data must be K bytes and parity must be ParitySymbols bytes.
+func (c *Codec) Encode(data, parity []byte) error {
+	if len(data) != c.k || len(parity) != c.nroots {
+		return ErrInvalidSize
+	}
+	if c.dualBasis {
+		var tmp [nn]byte
+		for i, v := range data {
+			tmp[i] = c.fromDual[v]
+		}
+		c.encode(tmp[:c.k], parity)
+		for i, v := range parity {
+			parity[i] = c.toDual[v]
+		}
+		return nil
+	}
+	c.encode(data, parity)
+	return nil
+}
+
+// encode calculates the parity using a linear feedback shift register.
+func (c *Codec) encode(data, bb []byte) {
+	f := c.f
+	nroots := c.nroots
+	clear(bb)
+	for _, v := range data {
+		feedback := int(f.indexOf[v^bb[0]])
+		if feedback != a0 {
+			for j := 1; j < nroots; j++ {
+				if g := int(c.genPoly[nroots-j]); g != a0 {
+					bb[j] ^= f.alphaTo[modnn(feedback+g)]
+				}
+			}
+		}
+		copy(bb, bb[1:])
+		if feedback != a0 {
+			bb[nroots-1] = f.alphaTo[modnn(feedback+int(c.genPoly[0]))]
+		} else {
+			bb[nroots-1] = 0
+		}
+	}
+}
+
+// Decode corrects errors and erasures in a codeword in place.
+// codeword must be N bytes, the message followed by the parity.
+// erasures contains the positions of known bad symbols in the codeword
+// and may be nil.
+//
+// The number of corrected symbols is returned.
+// If the codeword cannot be corrected ErrTooManyErrors is returned and
+// the codeword is left unchanged.
+// Note that a codeword with too many errors may also be miscorrected into
+// another valid codeword. This cannot be detected.
+func (c *Codec) Decode(codeword []byte, erasures []int) (int, error) {
+	if len(codeword) != c.n {
+		return 0, ErrInvalidSize
+	}
+	if len(erasures) > c.nroots {
+		return 0, ErrInvalidErasure
+	}
+	var seen [nn]bool
+	for _, pos := range erasures {
+		if pos < 0 || pos >= c.n || seen[pos] {
+			return 0, ErrInvalidErasure
+		}
+		seen[pos] = true
+	}
+	if !c.dualBasis {
+		return c.decode(codeword, erasures)
+	}
+	var tmp [nn]byte
+	for i, v := range codeword {
+		tmp[i] = c.fromDual[v]
+	}
+	n, err := c.decode(tmp[:c.n], erasures)
+	if err != nil {
+		return n, err
+	}
+	for i, v := range tmp[:c.n] {
+		codeword[i] = c.toDual[v]
+	}
+	return n, nil
+}
+
+// decode is based on the decoder by Phil Karn, KA9Q.
+func (c *Codec) decode(data []byte, erasures []int) (int, error) {
+	f := c.f
+	nroots := c.nroots
+	noEras := len(erasures)
+	alphaTo := func(x int) byte { return f.alphaTo[x] }
+	indexOf := func(x byte) int { return int(f.indexOf[x]) }
+
+	// Form the syndromes; i.e., evaluate data(x) at roots of g(x).
+	s := make([]int, nroots)
+	for i := range s {
+		s[i] = int(data[0])
+	}
+	for _, v := range data[1:] {
+		for i := range s {
+			if s[i] == 0 {
+				s[i] = int(v)
+			} else {
+				s[i] = int(v ^ alphaTo(modnn(indexOf(byte(s[i]))+(c.fcr+i)*c.prim)))
+			}
+		}
+	}
+
+	// Convert syndromes to index form, checking for nonzero condition.
+	synError := 0
+	for i := range s {
+		synError |= s[i]
+		s[i] = indexOf(byte(s[i]))
+	}
+	if synError == 0 {
+		// The codeword is valid.
+		return 0, nil
+	}
+
+	lambda := make([]byte, nroots+1)
+	lambda[0] = 1
+	if noEras > 0 {
+		// Init lambda to be the erasure locator polynomial.
+		lambda[1] = alphaTo(modnn(c.prim * (nn - 1 - (erasures[0] + c.pad))))
+		for i := 1; i < noEras; i++ {
+			u := modnn(c.prim * (nn - 1 - (erasures[i] + c.pad)))
+			for j := i + 1; j > 0; j-- {
+				tmp := indexOf(lambda[j-1])
+				if tmp != a0 {
+					lambda[j] ^= alphaTo(modnn(u + tmp))
+				}
+			}
+		}
+	}
+	b := make([]int, nroots+1)
+	t := make([]byte, nroots+1)
+	for i := range b {
+		b[i] = indexOf(lambda[i])
+	}
+
+	// Berlekamp-Massey algorithm to determine the error+erasure locator polynomial.
+	el := noEras
+	for r := noEras + 1; r <= nroots; r++ {
+		// Compute discrepancy at the r-th step in poly-form.
+		var discr byte
+		for i := 0; i < r; i++ {
+			if lambda[i] != 0 && s[r-i-1] != a0 {
+				discr ^= alphaTo(modnn(indexOf(lambda[i]) + s[r-i-1]))
+			}
+		}
+		discrR := indexOf(discr)
+		if discrR == a0 {
+			// B(x) <-- x*B(x)
+			copy(b[1:], b[:nroots])
+			b[0] = a0
+			continue
+		}
+		// T(x) <-- lambda(x) - discr*x*b(x)
+		t[0] = lambda[0]
+		for i := 0; i < nroots; i++ {
+			if b[i] != a0 {
+				t[i+1] = lambda[i+1] ^ alphaTo(modnn(discrR+b[i]))
+			} else {
+				t[i+1] = lambda[i+1]
+			}
+		}
+		if 2*el <= r+noEras-1 {
+			el = r + noEras - el
+			// B(x) <-- inv(discr) * lambda(x)
+			for i := range b {
+				if lambda[i] == 0 {
+					b[i] = a0
+				} else {
+					b[i] = modnn(indexOf(lambda[i]) - discrR + nn)
+				}
+			}
+		} else {
+			// B(x) <-- x*B(x)
+			copy(b[1:], b[:nroots])
+			b[0] = a0
+		}
+		copy(lambda, t)
+	}
+
+	// Convert lambda to index form and compute deg(lambda(x)).
+	lambdaIdx := make([]int, nroots+1)
+	degLambda := 0
+	for i := range lambda {
+		lambdaIdx[i] = indexOf(lambda[i])
+		if lambdaIdx[i] != a0 {
+			degLambda = i
+		}
+	}
+	if degLambda == 0 {
+		return 0, ErrTooManyErrors
+	}
+
+	// Find roots of the error+erasure locator polynomial by Chien search.
+	reg := make([]int, nroots+1)
+	copy(reg[1:], lambdaIdx[1:])
+	root := make([]int, 0, degLambda)
+	loc := make([]int, 0, degLambda)
+	for i, k := 1, c.iprim-1; i <= nn; i, k = i+1, modnn(k+c.iprim) {
+		q := byte(1) // lambda[0] is always 0 in index form.
+		for j := degLambda; j > 0; j-- {
+			if reg[j] != a0 {
+				reg[j] = modnn(reg[j] + j)
+				q ^= alphaTo(reg[j])
+			}
+		}
+		if q != 0 {
+			continue
+		}
+		// Store root (index-form) and error location number.
+		root = append(root, i)
+		loc = append(loc, k)
+		if len(root) == degLambda {
+			break
+		}
+	}
+	if len(root) != degLambda {
+		// deg(lambda) unequal to number of roots => uncorrectable error detected.
+		return 0, ErrTooManyErrors
+	}
+
+	// Compute err+eras evaluator poly omega(x) = s(x)*lambda(x)
+	// (modulo x**nroots) in index form.
+	degOmega := degLambda - 1
+	omega := make([]int, degOmega+1)
+	for i := range omega {
+		var tmp byte
+		for j := i; j >= 0; j-- {
+			if s[i-j] != a0 && lambdaIdx[j] != a0 {
+				tmp ^= alphaTo(modnn(s[i-j] + lambdaIdx[j]))
+			}
+		}
+		omega[i] = indexOf(tmp)
+	}
+
+	// Compute error values in poly-form.
+	// num1 = omega(inv(X(l))), num2 = inv(X(l))**(fcr-1)
+	// and den = lambda_pr(inv(X(l))).
+	fix := make([]byte, len(root))
+	for j := range root {
+		if loc[j] < c.pad {
+			// Error in the part of the codeword removed by shortening.
+			return 0, ErrTooManyErrors
+		}
+		var num1 byte
+		for i := degOmega; i >= 0; i-- {
+			if omega[i] != a0 {
+				num1 ^= alphaTo(modnn(omega[i] + i*root[j]))
+			}
+		}
+		num2 := alphaTo(modnn(root[j]*(c.fcr+nn-1) + nn))
+		var den byte
+		// lambda[i+1] for i even is the formal derivative lambda_pr of lambda[i].
+		for i := min(degLambda, nroots-1) &^ 1; i >= 0; i -= 2 {
+			if lambdaIdx[i+1] != a0 {
+				den ^= alphaTo(modnn(lambdaIdx[i+1] + i*root[j]))
+			}
+		}
+		if den == 0 {
+			return 0, ErrTooManyErrors
+		}
+		if num1 != 0 {
+			fix[j] = alphaTo(modnn(indexOf(num1) + indexOf(num2) + nn - indexOf(den)))
+		}
+	}
+	// Apply error values to the data.
+	for j, v := range fix {
+		data[loc[j]-c.pad] ^= v
+	}
+	return len(root), nil
+}
@@ -0,0 +1,277 @@
+package codeword
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestQRVector(t *testing.T) {
+	// "HELLO WORLD" encoded as a version 1-M QR code.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	c, err := New(len(data)+len(want), len(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parity := make([]byte, len(want))
+	if err := c.Encode(data, parity); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parity, want) {
+		t.Fatalf("got parity %v, want %v", parity, want)
+	}
+}
+
+func TestDualBasisTables(t *testing.T) {
+	toDual, fromDual := dualBasisTables()
+	want := []byte{0x00, 0x7b, 0xaf, 0xd4, 0x99, 0xe2, 0x36, 0x4d, 0xfa, 0x81}
+	if !bytes.Equal(toDual[:len(want)], want) {
+		t.Fatalf("got %x, want %x", toDual[:len(want)], want)
+	}
+	for i := range 256 {
+		if fromDual[toDual[i]] != byte(i) {
+			t.Fatalf("dual basis conversion of %d does not round trip", i)
+		}
+	}
+}
+
+func TestIsomorphism(t *testing.T) {
+	dst, err := newField(defaultPoly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, poly := range []int{0x11d, 0x187, 0x12b, 0x1f5} {
+		f, err := newField(poly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, from := f.isomorphism(dst)
+		for a := range 256 {
+			if from[to[a]] != byte(a) {
+				t.Fatalf("poly %x: mapping of %d does not round trip", poly, a)
+			}
+			for b := range 256 {
+				if to[byte(a)^byte(b)] != to[a]^to[b] {
+					t.Fatalf("poly %x: addition not preserved", poly)
+				}
+				if to[f.mul(byte(a), byte(b))] != dst.mul(to[a], to[b]) {
+					t.Fatalf("poly %x: multiplication not preserved", poly)
+				}
+			}
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, test := range []struct {
+		n, k int
+		opts []Option
+		err  error
+	}{
+		{255, 223, nil, nil},
+		{255, 223, []Option{WithCCSDS()}, nil},
+		{256, 223, nil, ErrInvalidParams},
+		{10, 10, nil, ErrInvalidParams},
+		{10, 0, nil, ErrInvalidParams},
+		{255, 223, []Option{WithGenerator(5)}, ErrInvalidParams},
+		{255, 223, []Option{WithFirstRoot(255)}, ErrInvalidParams},
+		{255, 223, []Option{WithFieldPolynomial(0x100)}, ErrInvalidPolynomial},
+		{255, 223, []Option{WithFieldPolynomial(0x11b)}, ErrInvalidPolynomial},
+	} {
+		_, err := New(test.n, test.k, test.opts...)
+		if err != test.err {
+			t.Errorf("New(%d, %d): expected %v, got %v", test.n, test.k, test.err, err)
+		}
+	}
+}
+
+func testCodes() map[string]func() (*Codec, error) {
+	return map[string]func() (*Codec, error){
+		"RS(255,223)":      func() (*Codec, error) { return New(255, 223) },
+		"RS(255,239)-fcr1": func() (*Codec, error) { return New(255, 239, WithFirstRoot(1)) },
+		"RS(26,16)":        func() (*Codec, error) { return New(26, 16) },
+		"RS(12,4)-0x12d": func() (*Codec, error) {
+			return New(12, 4, WithFieldPolynomial(0x12d), WithGenerator(7), WithFirstRoot(5))
+		},
+		"CCSDS(255,223)": func() (*Codec, error) { return New(255, 223, WithCCSDS()) },
+		"CCSDS(160,128)": func() (*Codec, error) { return New(160, 128, WithCCSDS()) },
+		"RS(255,253)-0x187": func() (*Codec, error) {
+			return New(255, 253, WithFieldPolynomial(0x187), WithGenerator(11), WithFirstRoot(112))
+		},
+	}
+}
+
+func TestDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for name, fn := range testCodes() {
+		t.Run(name, func(t *testing.T) {
+			c, err := fn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cw := make([]byte, c.N())
+			for range 200 {
+				rng.Read(cw[:c.K()])
+				if err := c.Encode(cw[:c.K()], cw[c.K():]); err != nil {
+					t.Fatal(err)
+				}
+				n, err := c.Decode(cw, nil)
+				if err != nil || n != 0 {
+					t.Fatalf("valid codeword: got %d, %v", n, err)
+				}
+				want := append([]byte{}, cw...)
+
+				// Random number of errors and erasures within the capability.
+				nroots := c.ParitySymbols()
+				eras := rng.Intn(nroots + 1)
+				errs := rng.Intn((nroots-eras)/2 + 1)
+				perm := rng.Perm(c.N())
+				erasures := perm[:eras]
+				for _, pos := range perm[:eras+errs] {
+					cw[pos] ^= byte(1 + rng.Intn(255))
+				}
+				n, err = c.Decode(cw, erasures)
+				if err != nil {
+					t.Fatalf("%d erasures, %d errors: %v", eras, errs, err)
+				}
+				if !bytes.Equal(cw, want) {
+					t.Fatalf("%d erasures, %d errors: codeword not corrected", eras, errs)
+				}
+				if n < errs || n > eras+errs {
+					t.Fatalf("%d erasures, %d errors: got %d corrections", eras, errs, n)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	c, err := New(26, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw := make([]byte, 26)
+	if _, err := c.Decode(cw[:25], nil); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+	if _, err := c.Decode(cw, []int{26}); err != ErrInvalidErasure {
+		t.Errorf("expected %v, got %v", ErrInvalidErasure, err)
+	}
+	if _, err := c.Decode(cw, []int{1, 1}); err != ErrInvalidErasure {
+		t.Errorf("expected %v, got %v", ErrInvalidErasure, err)
+	}
+	if _, err := c.Decode(cw, make([]int, 11)); err != ErrInvalidErasure {
+		t.Errorf("expected %v, got %v", ErrInvalidErasure, err)
+	}
+	if err := c.Encode(cw[:15], cw[16:]); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+
+	// Too many errors should mostly be detected, and never panic.
+	rng := rand.New(rand.NewSource(0))
+	detected := 0
+	for range 100 {
+		rng.Read(cw[:16])
+		if err := c.Encode(cw[:16], cw[16:]); err != nil {
+			t.Fatal(err)
+		}
+		for _, pos := range rng.Perm(26)[:8] {
+			cw[pos] ^= byte(1 + rng.Intn(255))
+		}
+		orig := append([]byte{}, cw...)
+		if _, err := c.Decode(cw, nil); err == ErrTooManyErrors {
+			detected++
+			if !bytes.Equal(cw, orig) {
+				t.Fatal("codeword modified on failure")
+			}
+		}
+	}
+	if detected < 50 {
+		t.Errorf("only %d of 100 uncorrectable codewords detected", detected)
+	}
+}
+
+func TestEncodeBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for name, fn := range testCodes() {
+		t.Run(name, func(t *testing.T) {
+			c, err := fn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			const count = 1000
+			symbols := make([][]byte, c.N())
+			for i := range symbols {
+				symbols[i] = make([]byte, count)
+				if i < c.K() {
+					rng.Read(symbols[i])
+				}
+			}
+			if err := c.EncodeBatch(symbols); err != nil {
+				t.Fatal(err)
+			}
+			cw := make([]byte, c.N())
+			for j := range count {
+				for i := range cw {
+					cw[i] = symbols[i][j]
+				}
+				want := make([]byte, c.ParitySymbols())
+				if err := c.Encode(cw[:c.K()], want); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(cw[c.K():], want) {
+					t.Fatalf("codeword %d: batch parity mismatch", j)
+				}
+			}
+			if err := c.EncodeBatch(symbols[1:]); err != ErrInvalidSize {
+				t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+			}
+		})
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	c, err := New(255, 223)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cw := make([]byte, 255)
+	b.SetBytes(223)
+	for b.Loop() {
+		_ = c.Encode(cw[:223], cw[223:])
+	}
+}
+
+func BenchmarkEncodeBatch(b *testing.B) {
+	c, err := New(255, 223)
+	if err != nil {
+		b.Fatal(err)
+	}
+	symbols := make([][]byte, 255)
+	for i := range symbols {
+		symbols[i] = make([]byte, 4096)
+	}
+	b.SetBytes(223 * 4096)
+	for b.Loop() {
+		_ = c.EncodeBatch(symbols)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	c, err := New(255, 223)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cw := make([]byte, 255)
+	b.SetBytes(223)
+	for b.Loop() {
+		for i := range 16 {
+			cw[i*7] ^= 0x55
+		}
+		_, err := c.Decode(cw, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
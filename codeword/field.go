@@ -0,0 +1,110 @@
+package codeword
+
+import "errors"
+
+const (
+	// nn is the number of non-zero field elements, and the maximum codeword length.
+	nn = 255
+	// a0 is the log of zero in index form.
+	a0 = nn
+
+	// defaultPoly is the field polynomial used by the reedsolomon package.
+	defaultPoly = 0x11d
+)
+
+// ErrInvalidPolynomial is returned if the field polynomial is not primitive.
+var ErrInvalidPolynomial = errors.New("codeword: field polynomial is not primitive")
+
+// field is GF(2^8) generated by a primitive polynomial.
+type field struct {
+	poly    int
+	alphaTo [nn + 1]byte // alphaTo[a0] = 0
+	indexOf [nn + 1]byte // indexOf[0] = a0
+}
+
+func newField(poly int) (*field, error) {
+	if poly < 0x100 || poly > 0x1ff {
+		return nil, ErrInvalidPolynomial
+	}
+	f := field{poly: poly}
+	sr := 1
+	for i := range nn {
+		if i > 0 && sr == 1 {
+			// Cycle shorter than the field.
+			return nil, ErrInvalidPolynomial
+		}
+		f.indexOf[sr] = byte(i)
+		f.alphaTo[i] = byte(sr)
+		sr <<= 1
+		if sr&0x100 != 0 {
+			sr ^= poly
+		}
+	}
+	if sr != 1 {
+		return nil, ErrInvalidPolynomial
+	}
+	f.indexOf[0] = a0
+	f.alphaTo[a0] = 0
+	return &f, nil
+}
+
+// modnn reduces x modulo 255.
+func modnn(x int) int {
+	return x % nn
+}
+
+func (f *field) mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return f.alphaTo[modnn(int(f.indexOf[a])+int(f.indexOf[b]))]
+}
+
+// isomorphism returns tables mapping elements of f to and from the field
+// used by the reedsolomon package. The mapping preserves both addition and
+// multiplication, so any linear code over f can be computed in the other field.
+func (f *field) isomorphism(dst *field) (to, from [256]byte) {
+	// Find a root of f.poly in dst, which will be the image of alpha.
+	var beta int
+	for x := 1; x < 256; x++ {
+		// Evaluate the polynomial at x with Horner's rule.
+		var v byte
+		for bit := 8; bit >= 0; bit-- {
+			v = dst.mul(v, byte(x))
+			if f.poly&(1<<bit) != 0 {
+				v ^= 1
+			}
+		}
+		if v == 0 {
+			beta = x
+			break
+		}
+	}
+	logBeta := int(dst.indexOf[beta])
+	for i := range nn {
+		img := dst.alphaTo[modnn(i*logBeta)]
+		to[f.alphaTo[i]] = img
+		from[img] = f.alphaTo[i]
+	}
+	return to, from
+}
+
+// ccsdsTal is the CCSDS transformation matrix between the conventional
+// and the dual basis representation, as given in CCSDS 131.0-B.
+var ccsdsTal = [8]byte{0x8d, 0xef, 0xec, 0x86, 0xfa, 0x99, 0xaf, 0x7b}
+
+// dualBasisTables returns the tables converting from conventional to dual
+// basis representation and back.
+func dualBasisTables() (toDual, fromDual [256]byte) {
+	for i := range 256 {
+		var v byte
+		for k := range 8 {
+			if i&(1<<k) != 0 {
+				v ^= ccsdsTal[7-k]
+			}
+		}
+		toDual[i] = v
+		fromDual[v] = byte(i)
+	}
+	return toDual, fromDual
+}
@@ -0,0 +1,217 @@
+package reedsolomon
+
+import (
+	"slices"
+	"sort"
+)
+
+// FragmentDecoder decodes data shards from fragments of shards
+// that can arrive in any order, for example from different peers.
+//
+// Fragments are added with Add, and the coverage of each shard is tracked.
+// Any range of the data shards can be read as soon as at least DataShards
+// shards cover it, without waiting for complete shards.
+// Different parts of a range can be decoded from different shards.
+//
+// Leopard GF is supported, but each shard only covers
+// complete blocks of ShardSizeMultiple bytes.
+//
+// A FragmentDecoder is not safe for concurrent use.
+type FragmentDecoder struct {
+	enc        Encoder
+	dataShards int
+	size       int
+	unit       int
+	shards     [][]byte
+	cover      [][]fragmentRange
+}
+
+// fragmentRange is a range of bytes [start, end) of a shard.
+type fragmentRange struct {
+	start, end int
+}
+
+// NewFragmentDecoder returns a decoder for shards of shardSize bytes
+// encoded with enc.
+// shardSize must be a multiple of the ShardSizeMultiple of enc.
+func NewFragmentDecoder(enc Encoder, shardSize int) (*FragmentDecoder, error) {
+	ext, ok := enc.(Extensions)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	unit := ext.ShardSizeMultiple()
+	if shardSize <= 0 || shardSize%unit != 0 {
+		return nil, ErrInvalidShardSize
+	}
+	return &FragmentDecoder{
+		enc:        enc,
+		dataShards: ext.DataShards(),
+		size:       shardSize,
+		unit:       unit,
+		shards:     make([][]byte, ext.TotalShards()),
+		cover:      make([][]fragmentRange, ext.TotalShards()),
+	}, nil
+}
+
+// Add a fragment of shard shardIdx starting at offset in the shard.
+// The data is copied.
+// Overlapping fragments are allowed, but must contain the same data.
+func (d *FragmentDecoder) Add(shardIdx, offset int, data []byte) error {
+	if shardIdx < 0 || shardIdx >= len(d.shards) {
+		return ErrInvShardNum
+	}
+	end := offset + len(data)
+	if offset < 0 || end > d.size {
+		return ErrShardSize
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if d.shards[shardIdx] == nil {
+		d.shards[shardIdx] = make([]byte, d.size)
+	}
+	copy(d.shards[shardIdx][offset:], data)
+
+	// Insert and merge with overlapping and adjacent ranges.
+	cover := d.cover[shardIdx]
+	i := sort.Search(len(cover), func(i int) bool { return cover[i].end >= offset })
+	j := i
+	for j < len(cover) && cover[j].start <= end {
+		offset = min(offset, cover[j].start)
+		end = max(end, cover[j].end)
+		j++
+	}
+	d.cover[shardIdx] = slices.Replace(cover, i, j, fragmentRange{start: offset, end: end})
+	return nil
+}
+
+// covers returns whether shard idx covers [start, end).
+// Only complete blocks of the shard size multiple are considered covered.
+func (d *FragmentDecoder) covers(idx, start, end int) bool {
+	cover := d.cover[idx]
+	i := sort.Search(len(cover), func(i int) bool { return cover[i].end > start })
+	if i == len(cover) {
+		return false
+	}
+	r := d.aligned(cover[i])
+	return r.start <= start && r.end >= end
+}
+
+// aligned returns r shrunk to complete blocks of the shard size multiple.
+func (d *FragmentDecoder) aligned(r fragmentRange) fragmentRange {
+	r.start = (r.start + d.unit - 1) / d.unit * d.unit
+	if r.end != d.size {
+		r.end = r.end / d.unit * d.unit
+	}
+	return r
+}
+
+// segments splits [offset, offset+length), expanded to complete blocks,
+// at the boundaries of the covered ranges, and calls fn with each segment
+// and the shards covering it.
+// It stops and returns false if fn returns false.
+func (d *FragmentDecoder) segments(offset, length int, fn func(start, end int, present []int) bool) bool {
+	start := offset / d.unit * d.unit
+	end := min(d.size, (offset+length+d.unit-1)/d.unit*d.unit)
+	points := []int{start, end}
+	for _, cover := range d.cover {
+		for _, r := range cover {
+			r = d.aligned(r)
+			for _, p := range []int{r.start, r.end} {
+				if p > start && p < end {
+					points = append(points, p)
+				}
+			}
+		}
+	}
+	slices.Sort(points)
+	points = slices.Compact(points)
+	present := make([]int, 0, len(d.shards))
+	for i := 1; i < len(points); i++ {
+		present = present[:0]
+		for idx := range d.shards {
+			if d.covers(idx, points[i-1], points[i]) {
+				present = append(present, idx)
+			}
+		}
+		if !fn(points[i-1], points[i], present) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready returns whether the range [offset, offset+length) of all data shards
+// can be read.
+func (d *FragmentDecoder) Ready(offset, length int) bool {
+	if offset < 0 || length <= 0 || offset+length > d.size {
+		return false
+	}
+	return d.segments(offset, length, func(_, _ int, present []int) bool {
+		return len(present) >= d.dataShards
+	})
+}
+
+// Read the data shards starting at offset into dst, decoding missing data as needed.
+// dst must contain DataShards slices. Data shards with a nil slice are not read.
+// All non-nil slices must have the same length.
+//
+// If the range cannot be decoded yet, ErrTooFewShards is returned.
+func (d *FragmentDecoder) Read(dst [][]byte, offset int) error {
+	if len(dst) != d.dataShards {
+		return ErrTooFewShards
+	}
+	length := -1
+	for _, b := range dst {
+		if b == nil {
+			continue
+		}
+		if length >= 0 && len(b) != length {
+			return ErrShardSize
+		}
+		length = len(b)
+	}
+	if length <= 0 {
+		return nil
+	}
+	if offset < 0 || offset+length > d.size {
+		return ErrShardSize
+	}
+	if !d.Ready(offset, length) {
+		return ErrTooFewShards
+	}
+
+	var err error
+	required := make([]bool, d.dataShards)
+	sub := make([][]byte, len(d.shards))
+	d.segments(offset, length, func(start, end int, present []int) bool {
+		// Use the first DataShards present shards, which prefers data shards.
+		present = present[:d.dataShards]
+		clear(sub)
+		clear(required)
+		for _, idx := range present {
+			sub[idx] = d.shards[idx][start:end]
+		}
+		reconstruct := false
+		for i := range dst {
+			if dst[i] != nil && sub[i] == nil {
+				required[i] = true
+				reconstruct = true
+			}
+		}
+		if reconstruct {
+			if err = d.enc.ReconstructSome(sub, required); err != nil {
+				return false
+			}
+		}
+		// Copy the part within the requested range.
+		from, to := max(start, offset), min(end, offset+length)
+		for i := range dst {
+			if dst[i] != nil {
+				copy(dst[i][from-offset:to-offset], sub[i][from-start:to-start])
+			}
+		}
+		return true
+	})
+	return err
+}
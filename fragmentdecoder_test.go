@@ -0,0 +1,138 @@
+package reedsolomon
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestFragmentDecoder(t *testing.T) {
+	const size = 64 * 100
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "leopard-ff8", opts: []Option{WithLeopardGF(true)}},
+		{name: "leopard-ff16", opts: []Option{WithLeopardGF16(true)}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc, err := New(5, 3, testOptions(test.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shards := newTestStripe(t, enc, size)
+			d, err := NewFragmentDecoder(enc, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Ready(0, size) {
+				t.Fatal("ready without fragments")
+			}
+
+			// Data shard 0 only has its first half,
+			// data shard 1 is missing entirely.
+			add := func(idx, start, end int) {
+				t.Helper()
+				if err := d.Add(idx, start, shards[idx][start:end]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			add(0, 0, size/2)
+			for _, idx := range []int{2, 3, 4, 5} {
+				add(idx, 0, size)
+			}
+			if !d.Ready(0, size/2) {
+				t.Fatal("first half not ready")
+			}
+			if d.Ready(size/2, 64) || d.Ready(0, size) {
+				t.Fatal("second half ready")
+			}
+			dst := make([][]byte, 5)
+			for i := range dst {
+				dst[i] = make([]byte, size/2)
+			}
+			if err := d.Read(dst, size/2); err != ErrTooFewShards {
+				t.Fatalf("expected %v, got %v", ErrTooFewShards, err)
+			}
+
+			// Add the rest of parity shard 6 in random fragments.
+			rng := rand.New(rand.NewSource(0))
+			for start := 0; start < size; {
+				end := min(size, start+1+rng.Intn(1000))
+				add(6, start, end)
+				start = end
+			}
+			if !d.Ready(0, size) {
+				t.Fatal("not ready")
+			}
+			// Read an unaligned window, spanning the end of shard 0.
+			const offset = size/2 - 100
+			dst = make([][]byte, 5)
+			for i := range dst {
+				dst[i] = make([]byte, 1000)
+			}
+			if err := d.Read(dst, offset); err != nil {
+				t.Fatal(err)
+			}
+			for i := range dst {
+				if !bytes.Equal(dst[i], shards[i][offset:offset+1000]) {
+					t.Fatalf("data shard %d mismatch", i)
+				}
+			}
+			// Only read data shard 1.
+			dst = make([][]byte, 5)
+			dst[1] = make([]byte, size)
+			if err := d.Read(dst, 0); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dst[1], shards[1]) {
+				t.Fatal("data shard 1 mismatch")
+			}
+		})
+	}
+}
+
+func TestFragmentDecoderErrors(t *testing.T) {
+	enc, err := New(4, 2, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFragmentDecoder(enc, 0); err != ErrInvalidShardSize {
+		t.Errorf("expected %v, got %v", ErrInvalidShardSize, err)
+	}
+	leo, err := New(4, 2, WithLeopardGF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFragmentDecoder(leo, 100); err != ErrInvalidShardSize {
+		t.Errorf("expected %v, got %v", ErrInvalidShardSize, err)
+	}
+	d, err := NewFragmentDecoder(enc, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Add(6, 0, []byte{1}); err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	if err := d.Add(0, 99, []byte{1, 2}); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+	if err := d.Read(make([][]byte, 3), 0); err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	if err := d.Read([][]byte{make([]byte, 10), make([]byte, 11), nil, nil}, 0); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+
+	// Overlapping and adjacent fragments are merged.
+	for _, r := range [][2]int{{10, 20}, {30, 40}, {15, 35}, {40, 50}, {0, 5}} {
+		if err := d.Add(0, r[0], make([]byte, r[1]-r[0])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []fragmentRange{{0, 5}, {10, 50}}
+	if got := d.cover[0]; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -95,6 +95,22 @@ func (r *leopardFF16) DecodeIdx(dst [][]byte, expectInput []bool, input [][]byte
 	return ErrNotSupported
 }
 
+func (r *leopardFF16) EncodeExtra(shards [][]byte, parityIndex int, out []byte) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF16) ParityDelta(dataIdx int, oldData, newData []byte, out [][]byte) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF16) UpdateRange(shards [][]byte, newData map[int][]byte, offset int) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF16) EncodeIdxRange(dataChunk []byte, idx, offset int, parity [][]byte) error {
+	return ErrNotSupported
+}
+
 type ffe uint16
 
 const (
@@ -91,6 +91,22 @@ func (r *leopardFF8) DecodeIdx(dst [][]byte, expectInput []bool, input [][]byte)
 	return ErrNotSupported
 }
 
+func (r *leopardFF8) EncodeExtra(shards [][]byte, parityIndex int, out []byte) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF8) ParityDelta(dataIdx int, oldData, newData []byte, out [][]byte) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF8) UpdateRange(shards [][]byte, newData map[int][]byte, offset int) error {
+	return ErrNotSupported
+}
+
+func (r *leopardFF8) EncodeIdxRange(dataChunk []byte, idx, offset int, parity [][]byte) error {
+	return ErrNotSupported
+}
+
 type ffe8 uint8
 
 const (
@@ -41,6 +41,16 @@ type options struct {
 	concReads  bool
 	concWrites bool
 	streamBS   int
+	pipeline   int
+	streamConc int
+
+	checkpoint       func(StreamCheckpoint)
+	checkpointBlocks int
+	progress         func(StreamProgress)
+	blockHook        func(StreamBlock) error
+
+	dropStreams   bool
+	streamDropped func(StreamDropped)
 }
 
 var defaultOptions = options{
@@ -146,6 +156,22 @@ func WithConcurrentStreamWrites(enabled bool) Option {
 	}
 }
 
+// WithStreamFailureTolerance will make Join, Verify and Reconstruct on streams
+// drop a shard stream that returns an error or ends before the other streams,
+// and continue using reconstruction as long as at least DataShards
+// streams remain.
+// All supplied shards are read, so there are spares to reconstruct from.
+// Join and ReconstructJoin will only tolerate failures if ReconstructJoin can be
+// used, and the shards must then be created by Split with the size given to Join.
+// If report is non-nil, it is called for each stream that is dropped.
+// Ignored if not used on a stream input.
+func WithStreamFailureTolerance(report func(StreamDropped)) Option {
+	return func(o *options) {
+		o.dropStreams = true
+		o.streamDropped = report
+	}
+}
+
 // WithInversionCache allows to control the inversion cache.
 // This will cache reconstruction matrices so they can be reused.
 // Enabled by default, or <= 64 shards for Leopard encoding.
@@ -166,6 +192,67 @@ func WithStreamBlockSize(n int) Option {
 	}
 }
 
+// WithStreamPipelineDepth enables pipelined stream encoding with up to n blocks in flight.
+// Reading the next block and writing the previous block will overlap
+// encoding of the current block.
+// Each block in flight uses a buffer of block size for all shards.
+// If n <= 1, blocks are read, encoded and written in sequence, which is the default.
+// See WithStreamConcurrency for EncodeAt, VerifyAt and ReconstructAt.
+// Ignored if not used on stream.
+func WithStreamPipelineDepth(n int) Option {
+	return func(o *options) {
+		o.pipeline = n
+	}
+}
+
+// WithStreamConcurrency sets the number of blocks EncodeAt, VerifyAt and
+// ReconstructAt will read, process and write in parallel.
+// Each block uses a buffer of block size for all shards,
+// so up to n * block size * total shards bytes are used.
+// If n <= 0, GOMAXPROCS is used, up to a maximum of 4, which is the default.
+// If n == 1, blocks are processed in sequence.
+// Ignored if not used on stream.
+func WithStreamConcurrency(n int) Option {
+	return func(o *options) {
+		o.streamConc = n
+	}
+}
+
+// WithStreamCheckpoint will call fn with a checkpoint after every n blocks
+// have been encoded and written by stream Encode and EncodeFrom,
+// and when encoding has completed.
+// Writers should be flushed before fn returns if they buffer output.
+// Encoding can be resumed from a checkpoint with EncodeFrom.
+// If n <= 0, a checkpoint is made after every block.
+// Ignored if not used on stream.
+func WithStreamCheckpoint(n int, fn func(StreamCheckpoint)) Option {
+	return func(o *options) {
+		o.checkpoint = fn
+		o.checkpointBlocks = max(n, 1)
+	}
+}
+
+// WithStreamProgress will call fn after each block processed by stream
+// Encode, EncodeFrom, Verify, Reconstruct, Split and Join.
+// fn is called from a single goroutine at the time.
+// Ignored if not used on stream.
+func WithStreamProgress(fn func(StreamProgress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithStreamBlockHook will call fn with each block processed by stream
+// Encode, EncodeFrom, Verify, Reconstruct, Split and Join, before it is written.
+// This can be used for checksumming or throttling.
+// If fn returns an error, the operation is stopped and the error returned.
+// Ignored if not used on stream.
+func WithStreamBlockHook(fn func(StreamBlock) error) Option {
+	return func(o *options) {
+		o.blockHook = fn
+	}
+}
+
 // WithSSSE3 allows to enable/disable SSSE3 instructions.
 // If not set, SSSE3 will be turned on or off automatically based on CPU ID information.
 func WithSSSE3(enabled bool) Option {
@@ -162,6 +162,39 @@ type Extensions interface {
 	// This allows merging of partial decodings from different sources.
 	// Not all implementations supports this. ErrNotSupported will be returned if not supported.
 	DecodeIdx(dst [][]byte, expectInput []bool, input [][]byte) error
+
+	// EncodeExtra will calculate parity shard number parityIndex and write it to out.
+	// Only the first DataShards of shards are used.
+	// parityIndex can be ParityShards or higher, which produces parity shards beyond
+	// the ones created by Encode, up to the number of rows the generator matrix provides.
+	// Extra parity shards can be given to the Reconstruct functions
+	// by extending shards, placing them at index DataShards+parityIndex.
+	// Not all implementations supports this. ErrNotSupported will be returned if not supported.
+	EncodeExtra(shards [][]byte, parityIndex int, out []byte) error
+
+	// ParityDelta calculates the change of each parity shard when data shard dataIdx
+	// changes from oldData to newData, and writes it to out.
+	// out must contain ParityShards slices with the same length as oldData and newData.
+	// The data can be any byte range of the shard; the delta applies to the same range
+	// of the parity shards. Use ApplyParityDelta to apply a delta to a parity shard.
+	// Not all implementations supports this. ErrNotSupported will be returned if not supported.
+	ParityDelta(dataIdx int, oldData, newData []byte, out [][]byte) error
+
+	// UpdateRange updates the parity shards for changes to a byte range of some data shards.
+	// newData contains the new content of the changed data shards, indexed by data shard number,
+	// starting at offset. All entries must have the same length.
+	// 'shards' must contain the old data of the changed data shards, and all parity shards.
+	// Only the range of the parity shards is updated.
+	// Note that the data shards in shards will *not* be updated.
+	// Not all implementations supports this. ErrNotSupported will be returned if not supported.
+	UpdateRange(shards [][]byte, newData map[int][]byte, offset int) error
+
+	// EncodeIdxRange will add parity for a part of a single data shard,
+	// starting at offset in the shard.
+	// This functions as EncodeIdx, but chunks of a data shard can be delivered
+	// in any order. Each byte of the data shard must be delivered exactly once.
+	// Not all implementations supports this. ErrNotSupported will be returned if not supported.
+	EncodeIdxRange(dataChunk []byte, idx, offset int, parity [][]byte) error
 }
 
 const (
@@ -186,6 +219,9 @@ type reedSolomon struct {
 	o            options
 	mPoolSz      int
 	mPool        sync.Pool // Pool for temp matrices, etc
+
+	extOnce   sync.Once
+	extParity [][]byte // All available parity rows, see extendedParity.
 }
 
 var _ = Extensions(&reedSolomon{})
@@ -472,8 +508,11 @@ func New(dataShards, parityShards int, opts ...Option) (Encoder, error) {
 			if len(row) < dataShards {
 				return nil, errors.New("coding matrix must contain at least dataShards columns")
 			}
-			r.m[dataShards+k] = make([]byte, dataShards)
-			copy(r.m[dataShards+k], row)
+			// Additional rows are available through EncodeExtra.
+			if k < parityShards {
+				r.m[dataShards+k] = make([]byte, dataShards)
+				copy(r.m[dataShards+k], row)
+			}
 		}
 	case r.o.fastOneParity && parityShards == 1:
 		r.m, err = buildXorMatrix(dataShards, r.totalShards)
@@ -632,6 +671,21 @@ func multiplyRowWithMatrix(row []byte, matrix [][]byte) []byte {
 
 // getDecodeMatrix returns the inverted matrix for decoding, using cached version if available
 func (r *reedSolomon) getDecodeMatrix(validIndices, invalidIndices []int) ([][]byte, error) {
+	// Matrices using extra parity rows are not cached,
+	// since the tree is keyed by the invalid rows only.
+	if validIndices[r.dataShards-1] >= r.totalShards {
+		ext := r.extendedParity()
+		subMatrix, _ := newMatrix(r.dataShards, r.dataShards)
+		for subMatrixRow, validIndex := range validIndices[:r.dataShards] {
+			if validIndex < r.totalShards {
+				copy(subMatrix[subMatrixRow], r.m[validIndex])
+			} else {
+				copy(subMatrix[subMatrixRow], ext[validIndex-r.dataShards])
+			}
+		}
+		return subMatrix.Invert()
+	}
+
 	// Attempt to get the cached inverted matrix out of the tree
 	// based on the indices of the invalid rows.
 	dataDecodeMatrix := r.tree.GetInvertedMatrix(invalidIndices)
@@ -756,6 +810,203 @@ func (r *reedSolomon) EncodeIdx(dataShard []byte, idx int, parity [][]byte) erro
 	return nil
 }
 
+// EncodeIdxRange will add parity for a part of a single data shard,
+// starting at offset in the shard.
+// Parity shards should start out zeroed. The caller must zero them before first call.
+// Chunks of a data shard can be delivered in any order, so encoding can
+// proceed as data arrives. Each byte of a data shard should only be delivered once.
+// There is no check for this.
+// The parity shards will be updated in the range of the chunk.
+func (r *reedSolomon) EncodeIdxRange(dataChunk []byte, idx, offset int, parity [][]byte) error {
+	if len(parity) != r.parityShards {
+		return ErrTooFewShards
+	}
+	if len(parity) == 0 || len(dataChunk) == 0 {
+		return nil
+	}
+	err := checkShards(parity, false)
+	if err != nil {
+		return err
+	}
+	end := offset + len(dataChunk)
+	if offset < 0 || end > len(parity[0]) {
+		return ErrShardSize
+	}
+	out := make([][]byte, len(parity))
+	for i, p := range parity {
+		out[i] = p[offset:end]
+	}
+	return r.EncodeIdx(dataChunk, idx, out)
+}
+
+// extendedParity returns all parity rows the generator matrix can provide,
+// including the ones used by Encode.
+// Rows are only available for matrices where the rows do not depend on the
+// number of parity shards. nil is returned if the matrix cannot be extended.
+func (r *reedSolomon) extendedParity() [][]byte {
+	r.extOnce.Do(func() {
+		var m matrix
+		var err error
+		switch {
+		case r.parityShards == 0:
+			return
+		case r.o.customMatrix != nil:
+			r.extParity = make([][]byte, len(r.o.customMatrix))
+			for i, row := range r.o.customMatrix {
+				r.extParity[i] = append([]byte(nil), row[:r.dataShards]...)
+			}
+			return
+		case r.o.fastOneParity && r.parityShards == 1, r.o.useJerasureMatrix:
+			return
+		case r.o.useCauchy:
+			m, err = buildMatrixCauchy(r.dataShards, 256)
+		case r.o.usePAR1Matrix:
+			m, err = buildMatrixPAR1(r.dataShards, 256)
+		default:
+			m, err = buildMatrix(r.dataShards, 256)
+		}
+		if err == nil {
+			r.extParity = m[r.dataShards:]
+		}
+	})
+	return r.extParity
+}
+
+// EncodeExtra will calculate parity shard number parityIndex and write it to out.
+// Only the first DataShards of shards are used.
+//
+// parityIndex can be ParityShards or higher, which produces parity shards
+// beyond the ones created by Encode. The default, Cauchy and PAR1 matrices
+// provide 256-DataShards parity shards in total, and a custom matrix provides
+// one parity shard per row.
+// Extra parity shards can be given to Reconstruct, ReconstructData and ReconstructSome
+// by extending shards, placing them at index DataShards+parityIndex.
+//
+// ErrNotSupported is returned for the Jerasure and XOR matrices,
+// and when there are no parity shards.
+func (r *reedSolomon) EncodeExtra(shards [][]byte, parityIndex int, out []byte) error {
+	if len(shards) < r.dataShards {
+		return ErrTooFewShards
+	}
+	rows := r.extendedParity()
+	if rows == nil {
+		return ErrNotSupported
+	}
+	if parityIndex < 0 || parityIndex >= len(rows) {
+		return ErrInvShardNum
+	}
+	data := shards[:r.dataShards]
+	err := checkShards(data, false)
+	if err != nil {
+		return err
+	}
+	if len(out) != len(data[0]) {
+		return ErrShardSize
+	}
+	r.codeSomeShards(rows[parityIndex:parityIndex+1], data, [][]byte{out}, len(out), true)
+	return nil
+}
+
+// ParityDelta calculates the change of each parity shard when data shard dataIdx
+// changes from oldData to newData, and writes it to out.
+// out must contain ParityShards slices with the same length as oldData and newData.
+//
+// The data can be any byte range of the shard; the delta applies to the same range
+// of the parity shards. This allows parity to be updated on a different machine
+// than the data, by sending each parity shard its delta.
+// Use ApplyParityDelta to apply a delta to a parity shard.
+func (r *reedSolomon) ParityDelta(dataIdx int, oldData, newData []byte, out [][]byte) error {
+	if len(out) != r.parityShards {
+		return ErrTooFewShards
+	}
+	if dataIdx < 0 || dataIdx >= r.dataShards {
+		return ErrInvShardNum
+	}
+	if len(oldData) != len(newData) {
+		return ErrShardSize
+	}
+	for _, o := range out {
+		if len(o) != len(oldData) {
+			return ErrShardSize
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+
+	delta := make([]byte, len(oldData))
+	copy(delta, oldData)
+	sliceXor(newData, delta, &r.o)
+	for i, o := range out {
+		galMulSlice(r.parity[i][dataIdx], delta, o, &r.o)
+	}
+	return nil
+}
+
+// ApplyParityDelta applies a delta calculated by ParityDelta to parity.
+// parity must be the same byte range of the parity shard as the delta was calculated for.
+func ApplyParityDelta(parity, delta []byte) error {
+	if len(parity) != len(delta) {
+		return ErrShardSize
+	}
+	sliceXor(delta, parity, &defaultOptions)
+	return nil
+}
+
+// UpdateRange updates the parity shards for changes to a byte range of some data shards.
+// newData contains the new content of the changed data shards, indexed by data shard number,
+// starting at offset. All entries must have the same length.
+// 'shards' must contain the old data of the changed data shards, and all parity shards.
+// Unchanged data shards can be nil.
+//
+// Only the range of the parity shards is read and updated,
+// so the cost is proportional to the size of the change.
+// Note that the data shards in shards will *not* be updated.
+func (r *reedSolomon) UpdateRange(shards [][]byte, newData map[int][]byte, offset int) error {
+	if len(shards) != r.totalShards {
+		return ErrTooFewShards
+	}
+	parity := shards[r.dataShards:]
+	for _, p := range parity {
+		if p == nil {
+			return ErrInvalidInput
+		}
+	}
+	if err := checkShards(parity, false); err != nil {
+		return err
+	}
+	size := -1
+	for idx, data := range newData {
+		if idx < 0 || idx >= r.dataShards {
+			return ErrInvShardNum
+		}
+		if shards[idx] == nil {
+			return ErrInvalidInput
+		}
+		if size >= 0 && len(data) != size {
+			return ErrShardSize
+		}
+		size = len(data)
+		if offset < 0 || offset+size > len(shards[idx]) || (len(parity) > 0 && offset+size > len(parity[0])) {
+			return ErrShardSize
+		}
+	}
+	if size <= 0 || len(parity) == 0 {
+		return nil
+	}
+
+	end := offset + size
+	delta := make([]byte, size)
+	for idx, data := range newData {
+		copy(delta, shards[idx][offset:end])
+		sliceXor(data, delta, &r.o)
+		for i, p := range parity {
+			galMulSliceXor(r.parity[i][idx], delta, p[offset:end], &r.o)
+		}
+	}
+	return nil
+}
+
 // ErrInvalidInput is returned if invalid input parameter of Update.
 var ErrInvalidInput = errors.New("invalid input")
 
@@ -1653,7 +1904,7 @@ func (r *reedSolomon) ReconstructData(shards [][]byte) error {
 // As the reconstructed shard set may contain missing parity shards,
 // calling the Verify function is likely to fail.
 func (r *reedSolomon) ReconstructSome(shards [][]byte, required []bool) error {
-	if len(required) != r.dataShards && len(required) != r.totalShards {
+	if len(required) != r.dataShards && len(required) != r.totalShards && len(required) != len(shards) {
 		return ErrInvalidInput
 	}
 	return r.reconstruct(shards, len(required) == r.dataShards, required)
@@ -1667,10 +1918,28 @@ func (r *reedSolomon) ReconstructSome(shards [][]byte, required []bool) error {
 //
 // If there are too few totalShards to reconstruct the missing
 // ones, ErrTooFewShards will be returned.
+//
+// shards may be longer than totalShards, if it contains
+// extra parity shards created by EncodeExtra.
 func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []bool) error {
-	if len(shards) != r.totalShards || required != nil && len(required) < r.dataShards {
+	if len(shards) < r.totalShards || required != nil && len(required) < r.dataShards {
 		return ErrTooFewShards
 	}
+	parity := r.parity
+	if len(shards) > r.totalShards {
+		parity = r.extendedParity()
+		if parity == nil {
+			return ErrNotSupported
+		}
+		if len(shards) > r.dataShards+len(parity) {
+			return ErrInvShardNum
+		}
+	}
+	totalShards := len(shards)
+	isRequired := func(i int) bool {
+		return required == nil || i < len(required) && required[i]
+	}
+
 	// Check arguments.
 	err := checkShards(shards, true)
 	if err != nil {
@@ -1684,19 +1953,17 @@ func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []boo
 	numberPresent := 0
 	dataPresent := 0
 	missingRequired := 0
-	for i := 0; i < r.totalShards; i++ {
+	for i := 0; i < totalShards; i++ {
 		if len(shards[i]) != 0 {
 			numberPresent++
 			if i < r.dataShards {
 				dataPresent++
 			}
-		} else if required != nil {
-			if required[i] {
-				missingRequired++
-			}
+		} else if required != nil && isRequired(i) {
+			missingRequired++
 		}
 	}
-	if numberPresent == r.totalShards || dataOnly && dataPresent == r.dataShards ||
+	if numberPresent == totalShards || dataOnly && dataPresent == r.dataShards ||
 		required != nil && missingRequired == 0 {
 		// Cool. All of the shards have data. We don't
 		// need to do anything.
@@ -1719,7 +1986,7 @@ func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []boo
 	validIndices := make([]int, r.dataShards)
 	invalidIndices := make([]int, 0)
 	subMatrixRow := 0
-	for matrixRow := 0; matrixRow < r.totalShards && subMatrixRow < r.dataShards; matrixRow++ {
+	for matrixRow := 0; matrixRow < totalShards && subMatrixRow < r.dataShards; matrixRow++ {
 		if len(shards[matrixRow]) != 0 {
 			subShards[subMatrixRow] = shards[matrixRow]
 			validIndices[subMatrixRow] = matrixRow
@@ -1738,12 +2005,12 @@ func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []boo
 	// Unified reconstruction: build a single decode matrix for all missing shards
 	// and reconstruct them in one codeSomeShards call.
 	outputCount := 0
-	outputs := make([][]byte, r.totalShards)
-	matrixRows := make([][]byte, r.totalShards)
+	outputs := make([][]byte, totalShards)
+	matrixRows := make([][]byte, totalShards)
 
 	// Count and prepare missing data shards
 	for iShard := 0; iShard < r.dataShards; iShard++ {
-		if len(shards[iShard]) == 0 && (required == nil || required[iShard]) {
+		if len(shards[iShard]) == 0 && isRequired(iShard) {
 			if cap(shards[iShard]) >= shardSize {
 				shards[iShard] = shards[iShard][0:shardSize]
 			} else {
@@ -1757,8 +2024,8 @@ func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []boo
 
 	if !dataOnly {
 		// Count and prepare missing parity shards
-		for iShard := r.dataShards; iShard < r.totalShards; iShard++ {
-			if len(shards[iShard]) == 0 && (required == nil || required[iShard]) {
+		for iShard := r.dataShards; iShard < totalShards; iShard++ {
+			if len(shards[iShard]) == 0 && isRequired(iShard) {
 				if cap(shards[iShard]) >= shardSize {
 					shards[iShard] = shards[iShard][0:shardSize]
 				} else {
@@ -1767,7 +2034,7 @@ func (r *reedSolomon) reconstruct(shards [][]byte, dataOnly bool, required []boo
 				outputs[outputCount] = shards[iShard]
 				// For parity shards, multiply parity row with inverted matrix
 				parityIdx := iShard - r.dataShards
-				matrixRows[outputCount] = multiplyRowWithMatrix(r.parity[parityIdx], dataDecodeMatrix)
+				matrixRows[outputCount] = multiplyRowWithMatrix(parity[parityIdx], dataDecodeMatrix)
 				outputCount++
 			}
 		}
@@ -794,6 +794,324 @@ func TestReconstructCustom(t *testing.T) {
 	}
 }
 
+func TestParityDelta(t *testing.T) {
+	const dataShards, parityShards, perShard = 7, 3, 10000
+	for name, opt := range map[string]Option{
+		"default":  nil,
+		"cauchy":   WithCauchyMatrix(),
+		"jerasure": WithJerasureMatrix(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var opts []Option
+			if opt != nil {
+				opts = append(opts, opt)
+			}
+			r, err := New(dataShards, parityShards, testOptions(opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shards := r.(Extensions).AllocAligned(perShard)
+			for _, s := range shards[:dataShards] {
+				fillRandom(s)
+			}
+			if err := r.Encode(shards); err != nil {
+				t.Fatal(err)
+			}
+
+			// Change a range of a data shard and update parity with the deltas.
+			const start, end = 1000, 5123
+			newData := make([]byte, end-start)
+			fillRandom(newData)
+			deltas := make([][]byte, parityShards)
+			for i := range deltas {
+				deltas[i] = make([]byte, end-start)
+			}
+			if err := r.(Extensions).ParityDelta(3, shards[3][start:end], newData, deltas); err != nil {
+				t.Fatal(err)
+			}
+			copy(shards[3][start:end], newData)
+			for i, d := range deltas {
+				if err := ApplyParityDelta(shards[dataShards+i][start:end], d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ok, err := r.Verify(shards)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("verification failed")
+			}
+
+			if err := r.(Extensions).ParityDelta(dataShards, newData, newData, deltas); err != ErrInvShardNum {
+				t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+			}
+			if err := r.(Extensions).ParityDelta(0, newData[1:], newData, deltas); err != ErrShardSize {
+				t.Errorf("expected %v, got %v", ErrShardSize, err)
+			}
+			if err := r.(Extensions).ParityDelta(0, newData, newData, deltas[1:]); err != ErrTooFewShards {
+				t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+			}
+			if err := ApplyParityDelta(newData[1:], newData); err != ErrShardSize {
+				t.Errorf("expected %v, got %v", ErrShardSize, err)
+			}
+		})
+	}
+	leo, err := New(dataShards, parityShards, WithLeopardGF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := leo.(Extensions).ParityDelta(0, nil, nil, nil); err != ErrNotSupported {
+		t.Errorf("expected %v, got %v", ErrNotSupported, err)
+	}
+}
+
+func TestUpdateRange(t *testing.T) {
+	const dataShards, parityShards, perShard = 7, 3, 1 << 16
+	r, err := New(dataShards, parityShards, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := r.(Extensions).AllocAligned(perShard)
+	for _, s := range shards[:dataShards] {
+		fillRandom(s)
+	}
+	if err := r.Encode(shards); err != nil {
+		t.Fatal(err)
+	}
+	oldParity := make([][]byte, parityShards)
+	for i := range oldParity {
+		oldParity[i] = bytes.Clone(shards[dataShards+i])
+	}
+
+	// Overwrite 4KiB of two data shards.
+	const offset, size = 12345, 4096
+	newData := map[int][]byte{1: make([]byte, size), 5: make([]byte, size)}
+	for _, d := range newData {
+		fillRandom(d)
+	}
+	// Unchanged data shards are not needed.
+	update := make([][]byte, len(shards))
+	copy(update, shards)
+	update[0], update[2] = nil, nil
+	if err := r.(Extensions).UpdateRange(update, newData, offset); err != nil {
+		t.Fatal(err)
+	}
+	for idx, d := range newData {
+		copy(shards[idx][offset:], d)
+	}
+	ok, err := r.Verify(shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+	// Parity outside the range must be untouched.
+	for i, p := range oldParity {
+		got := shards[dataShards+i]
+		if !bytes.Equal(got[:offset], p[:offset]) || !bytes.Equal(got[offset+size:], p[offset+size:]) {
+			t.Fatalf("parity %d changed outside range", i)
+		}
+	}
+
+	if err := r.(Extensions).UpdateRange(shards, newData, perShard-size+1); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+	if err := r.(Extensions).UpdateRange(shards, map[int][]byte{1: nil, 2: make([]byte, 10)}, 0); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+	if err := r.(Extensions).UpdateRange(shards, map[int][]byte{dataShards: nil}, 0); err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	if err := r.(Extensions).UpdateRange(update, map[int][]byte{0: make([]byte, 10)}, 0); err != ErrInvalidInput {
+		t.Errorf("expected %v, got %v", ErrInvalidInput, err)
+	}
+}
+
+func TestEncodeIdxRange(t *testing.T) {
+	const dataShards, parityShards, perShard = 6, 3, 50000
+	r, err := New(dataShards, parityShards, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := r.(Extensions).AllocAligned(perShard)
+	for _, s := range shards[:dataShards] {
+		fillRandom(s)
+	}
+	parity := r.(Extensions).AllocAligned(perShard)[:parityShards]
+
+	// Deliver chunks of all data shards in random order.
+	type chunk struct{ idx, start, end int }
+	var chunks []chunk
+	for idx := range dataShards {
+		for start := 0; start < perShard; {
+			end := min(perShard, start+1+rand.Intn(5000))
+			chunks = append(chunks, chunk{idx, start, end})
+			start = end
+		}
+	}
+	rand.Shuffle(len(chunks), func(i, j int) { chunks[i], chunks[j] = chunks[j], chunks[i] })
+	for _, c := range chunks {
+		if err := r.(Extensions).EncodeIdxRange(shards[c.idx][c.start:c.end], c.idx, c.start, parity); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Encode(shards); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range parity {
+		if !bytes.Equal(p, shards[dataShards+i]) {
+			t.Fatalf("parity %d does not match", i)
+		}
+	}
+
+	if err := r.(Extensions).EncodeIdxRange(make([]byte, 10), 0, perShard-9, parity); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+	if err := r.(Extensions).EncodeIdxRange(make([]byte, 10), dataShards, 0, parity); err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	if err := r.(Extensions).EncodeIdxRange(make([]byte, 10), 0, 0, parity[1:]); err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+}
+
+func TestEncodeExtra(t *testing.T) {
+	const dataShards, parityShards, extra, perShard = 6, 2, 4, 10000
+	custom := make([][]byte, parityShards+extra)
+	for i := range custom {
+		custom[i] = make([]byte, dataShards)
+		fillRandom(custom[i])
+	}
+	for name, opt := range map[string]Option{
+		"default": nil,
+		"cauchy":  WithCauchyMatrix(),
+		"par1":    WithPAR1Matrix(),
+		"custom":  WithCustomMatrix(custom),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var opts []Option
+			if opt != nil {
+				opts = append(opts, opt)
+			}
+			r, err := New(dataShards, parityShards, testOptions(opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ext := r.(Extensions)
+			shards := make([][]byte, dataShards+parityShards+extra)
+			for i := range shards {
+				shards[i] = make([]byte, perShard)
+			}
+			for _, s := range shards[:dataShards] {
+				fillRandom(s)
+			}
+			if err := r.Encode(shards[:dataShards+parityShards]); err != nil {
+				t.Fatal(err)
+			}
+			// Regular parity must match Encode.
+			out := make([]byte, perShard)
+			for i := range parityShards {
+				if err := ext.EncodeExtra(shards, i, out); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(out, shards[dataShards+i]) {
+					t.Fatalf("parity %d mismatch", i)
+				}
+			}
+			for i := parityShards; i < parityShards+extra; i++ {
+				if err := ext.EncodeExtra(shards, i, shards[dataShards+i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Extra parity must match an encoder with more parity shards.
+			big, err := New(dataShards, parityShards+extra, testOptions(opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := big.Verify(shards)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("extra parity does not match larger encoder")
+			}
+
+			// Reconstruct using the extra shards.
+			for _, lost := range [][]int{{0, 1, 2, 3, 4, 5}, {0, 2, 4, 6, 7, 9}, {1, 8, 11}} {
+				damaged := make([][]byte, len(shards))
+				copy(damaged, shards)
+				for _, i := range lost {
+					damaged[i] = nil
+				}
+				if err := r.Reconstruct(damaged); err != nil {
+					t.Fatal(err)
+				}
+				for i := range shards {
+					if !bytes.Equal(damaged[i], shards[i]) {
+						t.Fatalf("lost %v: shard %d mismatch", lost, i)
+					}
+				}
+				for _, i := range lost {
+					damaged[i] = nil
+				}
+				if err := r.ReconstructData(damaged); err != nil {
+					t.Fatal(err)
+				}
+				for i := range dataShards {
+					if !bytes.Equal(damaged[i], shards[i]) {
+						t.Fatalf("lost %v: data shard %d mismatch", lost, i)
+					}
+				}
+			}
+			damaged := make([][]byte, len(shards))
+			copy(damaged, shards)
+			for i := range extra + parityShards + 1 {
+				damaged[i] = nil
+			}
+			if err := r.Reconstruct(damaged); err != ErrTooFewShards {
+				t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+			}
+
+			maxParity := 256 - dataShards
+			if name == "custom" {
+				maxParity = len(custom)
+			}
+			if err := ext.EncodeExtra(shards, maxParity, out); err != ErrInvShardNum {
+				t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+			}
+			if err := ext.EncodeExtra(shards, maxParity-1, out); err != nil {
+				t.Error(err)
+			}
+			if err := ext.EncodeExtra(shards, 0, out[:10]); err != ErrShardSize {
+				t.Errorf("expected %v, got %v", ErrShardSize, err)
+			}
+		})
+	}
+
+	for name, opts := range map[string][]Option{
+		"jerasure": {WithJerasureMatrix()},
+		"xor":      {WithFastOneParityMatrix()},
+		"leopard":  {WithLeopardGF(true)},
+	} {
+		r, err := New(dataShards, 1, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shards := r.(Extensions).AllocAligned(64)
+		if err := r.(Extensions).EncodeExtra(shards, 1, make([]byte, 64)); err != ErrNotSupported {
+			t.Errorf("%s: expected %v, got %v", name, ErrNotSupported, err)
+		}
+		if name == "leopard" {
+			continue
+		}
+		if err := r.Reconstruct(append(shards, make([]byte, 64))); err != ErrNotSupported {
+			t.Errorf("%s: expected %v, got %v", name, ErrNotSupported, err)
+		}
+	}
+}
+
 func TestReconstructData(t *testing.T) {
 	parallelIfNotShort(t)
 	testReconstructData(t)
@@ -0,0 +1,108 @@
+package reedsolomon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Reshape converts one or more stripes encoded with from
+// to a single stripe encoded with to.
+//
+// Each stripe must contain from.TotalShards() shards of the same size.
+// Missing data shards are reconstructed in the stripes.
+// Missing parity shards are ignored.
+//
+// The data shards are kept in place: the returned stripe references
+// the data shards of the input stripes, ordered by stripe.
+// to must have DataShards equal to the number of stripes multiplied
+// by the data shards of from. This allows changing the parity count or
+// matrix of a stripe, or merging stripes, for example going from k to 2k
+// data shards by merging two stripes.
+// When merging stripes, Join will return the data of each stripe in order,
+// including any padding added by Split.
+//
+// Only parity shards that differ are calculated.
+// If both encoders are created by New without Leopard GF,
+// parity shards of a single input stripe that has the same encoding row
+// as a parity shard in the output are reused instead of being recalculated.
+// This means that going from 8+2 to 8+4 with the same matrix type
+// only calculates the 2 new parity shards.
+func Reshape(from, to Encoder, stripes ...[][]byte) ([][]byte, error) {
+	fromExt, ok := from.(Extensions)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	toExt, ok := to.(Extensions)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	if len(stripes) == 0 {
+		return nil, ErrTooFewShards
+	}
+	dataShards := fromExt.DataShards()
+	if toExt.DataShards() != dataShards*len(stripes) {
+		return nil, errors.Join(ErrInvalidInput, fmt.Errorf("cannot reshape %d stripes with %d data shards to %d data shards", len(stripes), dataShards, toExt.DataShards()))
+	}
+
+	size := 0
+	for _, stripe := range stripes {
+		if len(stripe) != fromExt.TotalShards() {
+			return nil, ErrTooFewShards
+		}
+		if err := from.ReconstructData(stripe); err != nil {
+			return nil, err
+		}
+		switch {
+		case size == 0:
+			size = len(stripe[0])
+		case len(stripe[0]) != size:
+			return nil, ErrShardSize
+		}
+	}
+	if size%toExt.ShardSizeMultiple() != 0 {
+		return nil, ErrInvalidShardSize
+	}
+
+	out := make([][]byte, toExt.TotalShards())
+	for i, stripe := range stripes {
+		copy(out[i*dataShards:], stripe[:dataShards])
+	}
+	toData := toExt.DataShards()
+
+	rsTo, ok := to.(*reedSolomon)
+	if !ok {
+		// We don't know the parity layout, so calculate all.
+		copy(out[toData:], AllocAligned(toExt.ParityShards(), size))
+		return out, to.Encode(out)
+	}
+
+	rsFrom, _ := from.(*reedSolomon)
+	var rows, outputs [][]byte
+	for i, row := range rsTo.parity {
+		if rsFrom != nil && len(stripes) == 1 {
+			if p := rsFrom.findParity(row, stripes[0], size); p != nil {
+				out[toData+i] = p
+				continue
+			}
+		}
+		out[toData+i] = AllocAligned(1, size)[0]
+		rows = append(rows, row)
+		outputs = append(outputs, out[toData+i])
+	}
+	if len(rows) > 0 {
+		rsTo.codeSomeShards(rows, out[:toData], outputs, size, true)
+	}
+	return out, nil
+}
+
+// findParity returns the parity shard in shards that is encoded with row,
+// or nil if none is present.
+func (r *reedSolomon) findParity(row []byte, shards [][]byte, size int) []byte {
+	for i, p := range r.parity {
+		if bytes.Equal(p, row) && len(shards[r.dataShards+i]) == size {
+			return shards[r.dataShards+i]
+		}
+	}
+	return nil
+}
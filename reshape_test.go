@@ -0,0 +1,152 @@
+package reedsolomon
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestStripe(t *testing.T, enc Encoder, size int) [][]byte {
+	t.Helper()
+	shards := enc.(Extensions).AllocAligned(size)
+	for _, s := range shards[:enc.(Extensions).DataShards()] {
+		fillRandom(s)
+	}
+	if err := enc.Encode(shards); err != nil {
+		t.Fatal(err)
+	}
+	return shards
+}
+
+func TestReshape(t *testing.T) {
+	const size = 10000
+	for _, test := range []struct {
+		name     string
+		from, to func() (Encoder, error)
+		reused   int
+	}{
+		{
+			name:   "8+2 to 8+4",
+			from:   func() (Encoder, error) { return New(8, 2, testOptions()...) },
+			to:     func() (Encoder, error) { return New(8, 4, testOptions()...) },
+			reused: 2,
+		},
+		{
+			name:   "8+4 to 8+2",
+			from:   func() (Encoder, error) { return New(8, 4, testOptions()...) },
+			to:     func() (Encoder, error) { return New(8, 2, testOptions()...) },
+			reused: 2,
+		},
+		{
+			name:   "cauchy 8+2 to 8+3",
+			from:   func() (Encoder, error) { return New(8, 2, testOptions(WithCauchyMatrix())...) },
+			to:     func() (Encoder, error) { return New(8, 3, testOptions(WithCauchyMatrix())...) },
+			reused: 2,
+		},
+		{
+			name: "vandermonde to cauchy",
+			from: func() (Encoder, error) { return New(8, 2, testOptions()...) },
+			to:   func() (Encoder, error) { return New(8, 2, testOptions(WithCauchyMatrix())...) },
+		},
+		{
+			name: "to leopard",
+			from: func() (Encoder, error) { return New(8, 2, testOptions()...) },
+			to:   func() (Encoder, error) { return New(8, 4, testOptions(WithLeopardGF(true))...) },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			from, err := test.from()
+			if err != nil {
+				t.Fatal(err)
+			}
+			to, err := test.to()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stripe := newTestStripe(t, from, size*64)
+			want := make([][]byte, 8)
+			for i := range want {
+				want[i] = append([]byte(nil), stripe[i]...)
+			}
+			// Lose a data shard and a parity shard.
+			stripe[3] = nil
+			stripe[9] = nil
+
+			out, err := Reshape(from, to, stripe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := to.Verify(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("verification failed")
+			}
+			for i := range want {
+				if &out[i][0] != &stripe[i][0] {
+					t.Fatalf("data shard %d was not kept in place", i)
+				}
+			}
+			reused := 0
+			for _, p := range out[8:] {
+				for _, s := range stripe[8:] {
+					if len(s) > 0 && &p[0] == &s[0] {
+						reused++
+					}
+				}
+			}
+			// Parity shard 9 was lost, so it cannot be reused.
+			if want := max(test.reused-1, 0); reused != want {
+				t.Fatalf("expected %d reused parity shards, got %d", want, reused)
+			}
+		})
+	}
+}
+
+func TestReshapeMerge(t *testing.T) {
+	from, err := New(4, 2, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := New(8, 3, testOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestStripe(t, from, 1000)
+	b := newTestStripe(t, from, 1000)
+	out, err := Reshape(from, to, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		if &out[i][0] != &a[i][0] || &out[4+i][0] != &b[i][0] {
+			t.Fatalf("data shard %d not in order", i)
+		}
+	}
+	ok, err := to.Verify(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+
+	// Errors
+	if _, err := Reshape(from, to, a); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected %v, got %v", ErrInvalidInput, err)
+	}
+	if _, err := Reshape(from, to, a, b[:5]); err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	c := newTestStripe(t, from, 500)
+	if _, err := Reshape(from, to, a, c); err != ErrShardSize {
+		t.Errorf("expected %v, got %v", ErrShardSize, err)
+	}
+	leo, err := New(8, 2, WithLeopardGF(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reshape(from, leo, a, b); err != ErrInvalidShardSize {
+		t.Errorf("expected %v, got %v", ErrInvalidShardSize, err)
+	}
+}
@@ -0,0 +1,32 @@
+package rfc5510
+
+// The field is GF(2^8) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1,
+// as required by RFC 5510 for m = 8. This is the same field as used by
+// the reedsolomon package.
+const fieldPoly = 0x11d
+
+var (
+	// gfExp[i] is alpha^i. The table is doubled to avoid reducing the sum of logs.
+	gfExp [2 * maxSymbols]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+	for i := range maxSymbols {
+		gfExp[i] = byte(x)
+		gfExp[i+maxSymbols] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= fieldPoly
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
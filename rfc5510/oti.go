@@ -0,0 +1,143 @@
+package rfc5510
+
+import (
+	"encoding/binary"
+)
+
+// OTISize is the size of the encoded FEC Object Transmission Information.
+const OTISize = 16
+
+// PayloadIDSize is the size of the encoded FEC Payload ID.
+const PayloadIDSize = 4
+
+// OTI is the FEC Object Transmission Information of an object,
+// as defined in RFC 5510.
+type OTI struct {
+	// TransferLength is the length of the object in bytes (L).
+	// Must be less than 2^48.
+	TransferLength uint64
+
+	// SymbolLength is the length of an encoding symbol in bytes (E).
+	SymbolLength uint16
+
+	// M is the length of a finite field element in bits.
+	// Only 8 is supported.
+	M uint8
+
+	// G is the number of encoding symbols per packet.
+	G uint8
+
+	// MaxBlockLength is the maximum number of source symbols per source block (B).
+	MaxBlockLength uint16
+
+	// MaxEncodingSymbols is the maximum number of encoding symbols
+	// per source block (max_n).
+	MaxEncodingSymbols uint16
+}
+
+// NewOTI returns the transmission information for an object of the given length,
+// sent with one symbol of symbolLen bytes per packet.
+// Source blocks will contain at most maxBlock source symbols,
+// and be extended to at most maxN encoding symbols.
+func NewOTI(length uint64, symbolLen, maxBlock, maxN int) (OTI, error) {
+	o := OTI{
+		TransferLength:     length,
+		SymbolLength:       uint16(symbolLen),
+		M:                  M,
+		G:                  1,
+		MaxBlockLength:     uint16(maxBlock),
+		MaxEncodingSymbols: uint16(maxN),
+	}
+	if symbolLen > 0xffff || maxBlock > 0xffff || maxN > 0xffff {
+		return o, ErrInvalidOTI
+	}
+	return o, o.validate()
+}
+
+func (o OTI) validate() error {
+	switch {
+	case o.M != M:
+		return ErrUnsupported
+	case o.TransferLength == 0 || o.TransferLength >= 1<<48:
+		return ErrInvalidOTI
+	case o.SymbolLength == 0 || o.G == 0:
+		return ErrInvalidOTI
+	case o.MaxBlockLength == 0 || o.MaxBlockLength > o.MaxEncodingSymbols:
+		return ErrInvalidOTI
+	case int(o.MaxEncodingSymbols) > maxSymbols:
+		return ErrInvalidOTI
+	}
+	if o.numBlocks() > maxBlocks {
+		return ErrInvalidOTI
+	}
+	return nil
+}
+
+// MarshalBinary returns the OTI in the encoding given in RFC 5510.
+// This is also the content of the EXT_FTI header extension, after the
+// header extension type and length.
+//
+//	Transfer Length (48 bits) | Reserved (16 bits) | Encoding Symbol Length (16 bits) |
+//	m (8 bits) | G (8 bits) | Max Source Block Length (16 bits) |
+//	Max Number of Encoding Symbols (16 bits)
+func (o OTI) MarshalBinary() ([]byte, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	var dst [OTISize]byte
+	binary.BigEndian.PutUint64(dst[0:], o.TransferLength<<16)
+	binary.BigEndian.PutUint16(dst[8:], o.SymbolLength)
+	dst[10] = o.M
+	dst[11] = o.G
+	binary.BigEndian.PutUint16(dst[12:], o.MaxBlockLength)
+	binary.BigEndian.PutUint16(dst[14:], o.MaxEncodingSymbols)
+	return dst[:], nil
+}
+
+// UnmarshalBinary reads the OTI in the format written by MarshalBinary.
+// The reserved field is ignored.
+func (o *OTI) UnmarshalBinary(b []byte) error {
+	if len(b) < OTISize {
+		return ErrShortBuffer
+	}
+	v := OTI{
+		TransferLength:     binary.BigEndian.Uint64(b[0:]) >> 16,
+		SymbolLength:       binary.BigEndian.Uint16(b[8:]),
+		M:                  b[10],
+		G:                  b[11],
+		MaxBlockLength:     binary.BigEndian.Uint16(b[12:]),
+		MaxEncodingSymbols: binary.BigEndian.Uint16(b[14:]),
+	}
+	if err := v.validate(); err != nil {
+		return err
+	}
+	*o = v
+	return nil
+}
+
+// PayloadID is the FEC Payload ID of a packet, as defined in RFC 5510.
+type PayloadID struct {
+	// SBN is the source block number.
+	SBN uint32
+	// ESI is the encoding symbol ID of the first symbol in the packet.
+	ESI uint32
+}
+
+// MarshalBinary returns the payload ID for m = 8:
+// a 24 bit source block number followed by an 8 bit encoding symbol ID.
+func (p PayloadID) MarshalBinary() ([]byte, error) {
+	if p.SBN >= maxBlocks || p.ESI >= 1<<M {
+		return nil, ErrInvalidPayloadID
+	}
+	return binary.BigEndian.AppendUint32(nil, p.SBN<<M|p.ESI), nil
+}
+
+// UnmarshalBinary reads a payload ID in the format written by MarshalBinary.
+func (p *PayloadID) UnmarshalBinary(b []byte) error {
+	if len(b) < PayloadIDSize {
+		return ErrShortBuffer
+	}
+	v := binary.BigEndian.Uint32(b)
+	*p = PayloadID{SBN: v >> M, ESI: v & (1<<M - 1)}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Package rfc5510 implements the Reed-Solomon FEC scheme over GF(2^8)
+// defined in RFC 5510 (FEC Encoding ID 2, m = 8), for use with FLUTE/ALC.
+//
+// It provides the FEC Object Transmission Information (OTI),
+// the FEC Payload ID, the partitioning of objects into source blocks
+// defined in RFC 5052, section 9.1, and encoding and decoding of source
+// blocks with the generator matrix mandated by the RFC, so repair symbols
+// interoperate with other RFC 5510 implementations.
+package rfc5510
+
+import (
+	"errors"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// M is the only supported field size in bits.
+const M = 8
+
+const (
+	// maxSymbols is the maximum number of encoding symbols in a block.
+	maxSymbols = 1<<M - 1
+	// maxBlocks is the number of source blocks addressable by the payload ID.
+	maxBlocks = 1 << (32 - M)
+)
+
+var (
+	// ErrUnsupported is returned if the OTI uses a field size other than 8 bits.
+	ErrUnsupported = errors.New("rfc5510: only m = 8 is supported")
+
+	// ErrInvalidOTI is returned if the OTI contains invalid values.
+	ErrInvalidOTI = errors.New("rfc5510: invalid object transmission information")
+
+	// ErrInvalidPayloadID is returned if a payload ID is out of range.
+	ErrInvalidPayloadID = errors.New("rfc5510: invalid payload ID")
+
+	// ErrShortBuffer is returned if an encoded value is truncated.
+	ErrShortBuffer = errors.New("rfc5510: buffer too short")
+
+	// ErrInvalidSize is returned if the object or the symbols
+	// do not match the OTI.
+	ErrInvalidSize = errors.New("rfc5510: invalid size")
+
+	// ErrTooFewSymbols is returned if a block cannot be decoded
+	// because less than k symbols were received.
+	ErrTooFewSymbols = errors.New("rfc5510: too few symbols")
+)
+
+// numBlocks returns the number of source blocks (N).
+func (o OTI) numBlocks() int {
+	t := o.numSymbols()
+	b := uint64(o.MaxBlockLength)
+	return int((t + b - 1) / b)
+}
+
+// numSymbols returns the number of source symbols in the object (T).
+func (o OTI) numSymbols() uint64 {
+	e := uint64(o.SymbolLength)
+	return (o.TransferLength + e - 1) / e
+}
+
+// Block describes a source block.
+type Block struct {
+	// SBN is the source block number.
+	SBN int
+	// Offset is the position of the block in the object.
+	Offset uint64
+	// Length is the number of object bytes in the block.
+	// Only the last block can have a length that is not a multiple
+	// of the symbol length.
+	Length int
+	// K is the number of source symbols.
+	K int
+	// N is the number of encoding symbols, including the source symbols.
+	N int
+}
+
+// Blocks returns the source blocks of the object, using the block
+// partitioning algorithm from RFC 5052, section 9.1.
+// The first blocks will contain one source symbol more than the rest
+// if the symbols cannot be divided evenly.
+//
+// The number of encoding symbols for a block with k source symbols is
+// floor(k * max_n / B), as given in RFC 5510.
+func (o OTI) Blocks() ([]Block, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	t := o.numSymbols()
+	n := uint64(o.numBlocks())
+	large := int((t + n - 1) / n)
+	small := int(t / n)
+	nLarge := int(t - uint64(small)*n)
+
+	blocks := make([]Block, n)
+	var offset uint64
+	for i := range blocks {
+		k := small
+		if i < nLarge {
+			k = large
+		}
+		length := uint64(k) * uint64(o.SymbolLength)
+		if offset+length > o.TransferLength {
+			length = o.TransferLength - offset
+		}
+		blocks[i] = Block{
+			SBN:    i,
+			Offset: offset,
+			Length: int(length),
+			K:      k,
+			N:      k * int(o.MaxEncodingSymbols) / int(o.MaxBlockLength),
+		}
+		offset += length
+	}
+	return blocks, nil
+}
+
+// Codec encodes and decodes the source blocks of an object.
+// A Codec is safe for concurrent use.
+type Codec struct {
+	oti    OTI
+	blocks []Block
+	// encs holds the encoder for each block size, keyed by k.
+	encs map[int]reedsolomon.Encoder
+}
+
+// New returns a codec for the object described by oti.
+func New(oti OTI) (*Codec, error) {
+	blocks, err := oti.Blocks()
+	if err != nil {
+		return nil, err
+	}
+	c := Codec{oti: oti, blocks: blocks, encs: make(map[int]reedsolomon.Encoder, 2)}
+	for _, b := range blocks {
+		if _, ok := c.encs[b.K]; ok || b.N == b.K {
+			continue
+		}
+		enc, err := reedsolomon.New(b.K, b.N-b.K, reedsolomon.WithCustomMatrix(repairMatrix(b.K, b.N)))
+		if err != nil {
+			return nil, err
+		}
+		c.encs[b.K] = enc
+	}
+	return &c, nil
+}
+
+// OTI returns the object transmission information.
+func (c *Codec) OTI() OTI {
+	return c.oti
+}
+
+// Blocks returns the source blocks of the object.
+// The returned slice must not be modified.
+func (c *Codec) Blocks() []Block {
+	return c.blocks
+}
+
+// Encode returns all encoding symbols of the source block sbn.
+// object must contain the complete object.
+// The returned slice contains the symbols in ESI order.
+// The source symbols are copied, and the last one is padded with zeros
+// if the block does not fill it completely.
+func (c *Codec) Encode(sbn int, object []byte) ([][]byte, error) {
+	if uint64(len(object)) != c.oti.TransferLength {
+		return nil, ErrInvalidSize
+	}
+	b, err := c.block(sbn)
+	if err != nil {
+		return nil, err
+	}
+	e := int(c.oti.SymbolLength)
+	buf := make([]byte, b.N*e)
+	copy(buf, object[b.Offset:b.Offset+uint64(b.Length)])
+	symbols := make([][]byte, b.N)
+	for i := range symbols {
+		symbols[i] = buf[i*e : (i+1)*e : (i+1)*e]
+	}
+	if enc := c.encs[b.K]; enc != nil {
+		if err := enc.Encode(symbols); err != nil {
+			return nil, err
+		}
+	}
+	return symbols, nil
+}
+
+// Decode recovers the source block sbn.
+// symbols must contain N entries indexed by ESI, with nil for
+// symbols that have not been received. At least K symbols must be present.
+// Missing source symbols are reconstructed in place.
+// The returned slice contains the block data without padding.
+func (c *Codec) Decode(sbn int, symbols [][]byte) ([]byte, error) {
+	b, err := c.block(sbn)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) != b.N {
+		return nil, ErrInvalidSize
+	}
+	present := 0
+	for _, s := range symbols {
+		if s == nil {
+			continue
+		}
+		if len(s) != int(c.oti.SymbolLength) {
+			return nil, ErrInvalidSize
+		}
+		present++
+	}
+	if present < b.K {
+		return nil, ErrTooFewSymbols
+	}
+	if enc := c.encs[b.K]; enc != nil {
+		if err := enc.ReconstructData(symbols); err != nil {
+			return nil, err
+		}
+	}
+	dst := make([]byte, 0, b.Length)
+	for _, s := range symbols[:b.K] {
+		dst = append(dst, s[:min(len(s), b.Length-len(dst))]...)
+	}
+	return dst, nil
+}
+
+func (c *Codec) block(sbn int) (Block, error) {
+	if sbn < 0 || sbn >= len(c.blocks) {
+		return Block{}, ErrInvalidPayloadID
+	}
+	return c.blocks[sbn], nil
+}
+
+// repairMatrix returns the rows of the generator matrix that produce the
+// n-k repair symbols of a block with k source symbols.
+//
+// RFC 5510, section 8.2 defines the generator matrix as
+// GM = V_{k,k}^-1 * V_{k,n}, where V_{k,n} is the Vandermonde matrix
+// with entry (i, j) set to (alpha^j)^i.
+// Encoding symbol j is then the value at alpha^j of the polynomial
+// of degree < k that passes through the source symbols at alpha^0 ... alpha^(k-1).
+// Entry (i, j) of GM is therefore the Lagrange basis polynomial L_i(alpha^j),
+// which is what is computed here.
+func repairMatrix(k, n int) [][]byte {
+	// denom[i] = prod_{t != i} (x_i - x_t), where x_t = alpha^t.
+	denom := make([]byte, k)
+	for i := range denom {
+		d := byte(1)
+		for t := range k {
+			if t != i {
+				d = gfMul(d, gfExp[i]^gfExp[t])
+			}
+		}
+		denom[i] = reedsolomon.Inv(d)
+	}
+	rows := make([][]byte, n-k)
+	for r := range rows {
+		x := gfExp[k+r]
+		row := make([]byte, k)
+		for i := range row {
+			v := denom[i]
+			for t := range k {
+				if t != i {
+					v = gfMul(v, x^gfExp[t])
+				}
+			}
+			row[i] = v
+		}
+		rows[r] = row
+	}
+	return rows
+}
@@ -0,0 +1,218 @@
+package rfc5510
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBlocks(t *testing.T) {
+	for _, test := range []struct {
+		length     uint64
+		e, b, maxN int
+		large      int
+		nLarge     int
+		numBlocks  int
+	}{
+		{length: 1000, e: 100, b: 10, maxN: 15, large: 10, nLarge: 0, numBlocks: 1},
+		{length: 1001, e: 100, b: 10, maxN: 15, large: 6, nLarge: 1, numBlocks: 2},
+		{length: 2500, e: 10, b: 100, maxN: 200, large: 84, nLarge: 1, numBlocks: 3},
+		{length: 1, e: 1000, b: 1, maxN: 1, large: 1, nLarge: 0, numBlocks: 1},
+	} {
+		oti, err := NewOTI(test.length, test.e, test.b, test.maxN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks, err := oti.Blocks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blocks) != test.numBlocks {
+			t.Fatalf("L=%d: expected %d blocks, got %d", test.length, test.numBlocks, len(blocks))
+		}
+		var offset uint64
+		nLarge := 0
+		for i, b := range blocks {
+			if b.SBN != i || b.Offset != offset {
+				t.Fatalf("L=%d: block %d has SBN %d, offset %d", test.length, i, b.SBN, b.Offset)
+			}
+			if b.K > test.b || b.N != b.K*test.maxN/test.b {
+				t.Fatalf("L=%d: block %d has k=%d, n=%d", test.length, i, b.K, b.N)
+			}
+			if b.K == test.large && test.nLarge > 0 {
+				nLarge++
+			}
+			offset += uint64(b.Length)
+		}
+		if offset != test.length {
+			t.Fatalf("L=%d: blocks cover %d bytes", test.length, offset)
+		}
+		if nLarge != test.nLarge {
+			t.Fatalf("L=%d: expected %d large blocks, got %d", test.length, test.nLarge, nLarge)
+		}
+	}
+}
+
+// TestGeneratorMatrix checks that the encoding symbols are the
+// evaluations of the message polynomial at alpha^j, which is the
+// definition of the generator matrix in RFC 5510.
+func TestGeneratorMatrix(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	eval := func(coeffs []byte, x byte) byte {
+		var v byte
+		for i := len(coeffs) - 1; i >= 0; i-- {
+			v = gfMul(v, x) ^ coeffs[i]
+		}
+		return v
+	}
+	for _, kn := range [][2]int{{1, 2}, {4, 6}, {20, 40}, {200, 255}} {
+		k, n := kn[0], kn[1]
+		oti, err := NewOTI(uint64(k), 1, k, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := New(oti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coeffs := make([]byte, k)
+		rng.Read(coeffs)
+		object := make([]byte, k)
+		for i := range object {
+			object[i] = eval(coeffs, gfExp[i])
+		}
+		symbols, err := c.Encode(0, object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, s := range symbols {
+			if want := eval(coeffs, gfExp[j]); s[0] != want {
+				t.Fatalf("k=%d, n=%d: symbol %d is %d, want %d", k, n, j, s[0], want)
+			}
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	oti, err := NewOTI(100_003, 64, 50, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(oti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := make([]byte, oti.TransferLength)
+	rng.Read(object)
+	var got []byte
+	for _, b := range c.Blocks() {
+		symbols, err := c.Encode(b.SBN, object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(symbols) != b.N {
+			t.Fatalf("expected %d symbols, got %d", b.N, len(symbols))
+		}
+		// Drop as many symbols as possible.
+		for _, i := range rng.Perm(b.N)[:b.N-b.K] {
+			symbols[i] = nil
+		}
+		short := slices.Clone(symbols)
+		short[slices.IndexFunc(short, func(s []byte) bool { return s != nil })] = nil
+		if _, err := c.Decode(b.SBN, short); err != ErrTooFewSymbols {
+			t.Errorf("expected %v, got %v", ErrTooFewSymbols, err)
+		}
+		data, err := c.Decode(b.SBN, symbols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, data...)
+	}
+	if !bytes.Equal(got, object) {
+		t.Fatal("decoded object mismatch")
+	}
+}
+
+func TestOTI(t *testing.T) {
+	oti, err := NewOTI(1<<40+5, 1400, 200, 255)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := oti.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x05, 0, 0, 0x05, 0x78, 8, 1, 0, 200, 0, 255}
+	if !bytes.Equal(b, want) {
+		t.Fatalf("got %x, want %x", b, want)
+	}
+	var got OTI
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got != oti {
+		t.Fatalf("got %+v, want %+v", got, oti)
+	}
+	b[10] = 16
+	if err := got.UnmarshalBinary(b); err != ErrUnsupported {
+		t.Errorf("expected %v, got %v", ErrUnsupported, err)
+	}
+	if err := got.UnmarshalBinary(b[:15]); err != ErrShortBuffer {
+		t.Errorf("expected %v, got %v", ErrShortBuffer, err)
+	}
+	for _, test := range []struct {
+		length     uint64
+		e, b, maxN int
+	}{
+		{0, 10, 10, 10},
+		{1 << 48, 10, 10, 10},
+		{100, 0, 10, 10},
+		{100, 10, 0, 10},
+		{100, 10, 11, 10},
+		{100, 10, 10, 256},
+		{1 << 40, 1, 1, 1},
+	} {
+		if _, err := NewOTI(test.length, test.e, test.b, test.maxN); err != ErrInvalidOTI {
+			t.Errorf("%+v: expected %v, got %v", test, ErrInvalidOTI, err)
+		}
+	}
+}
+
+func TestPayloadID(t *testing.T) {
+	id := PayloadID{SBN: 0x123456, ESI: 0x78}
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, []byte{0x12, 0x34, 0x56, 0x78}) {
+		t.Fatalf("got %x", b)
+	}
+	var got PayloadID
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Fatalf("got %+v, want %+v", got, id)
+	}
+	if _, err := (PayloadID{SBN: 1 << 24}).MarshalBinary(); err != ErrInvalidPayloadID {
+		t.Errorf("expected %v, got %v", ErrInvalidPayloadID, err)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	oti, err := NewOTI(200*1024, 1024, 200, 255)
+	if err != nil {
+		b.Fatal(err)
+	}
+	c, err := New(oti)
+	if err != nil {
+		b.Fatal(err)
+	}
+	object := make([]byte, oti.TransferLength)
+	b.SetBytes(int64(len(object)))
+	for b.Loop() {
+		_, _ = c.Encode(0, object)
+	}
+}
@@ -0,0 +1,221 @@
+package rlc
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// Symbol is a source symbol recovered by the decoder.
+type Symbol struct {
+	ESI  uint32
+	Data []byte
+}
+
+// equation is a repair symbol with all known source symbols removed.
+// Data is the sum of coeffs[esi] * source[esi] over the remaining unknown symbols.
+type equation struct {
+	coeffs map[uint32]byte
+	data   []byte
+}
+
+// Decoder recovers lost source symbols from repair symbols.
+//
+// The decoder keeps the source symbols within a decoding window of the
+// most recent ESIs seen. Lost symbols that fall out of the window are
+// given up, which bounds the latency and memory use of the decoder.
+// The decoding window should be at least as large as the encoding window.
+type Decoder struct {
+	size    int
+	window  int
+	started bool
+	highest uint32
+	known   map[uint32][]byte
+	eqs     []*equation
+	ll      reedsolomon.LowLevel
+}
+
+// NewDecoder returns a decoder for source symbols of symbolSize bytes,
+// which keeps a decoding window of window source symbols.
+func NewDecoder(symbolSize, window int) (*Decoder, error) {
+	if symbolSize <= 0 || window <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &Decoder{size: symbolSize, window: window, known: make(map[uint32][]byte)}, nil
+}
+
+// base returns the lowest ESI in the decoding window.
+func (d *Decoder) base() uint32 {
+	if d.highest < uint32(d.window) {
+		return 0
+	}
+	return d.highest - uint32(d.window) + 1
+}
+
+// advance moves the decoding window so it includes esi,
+// and removes everything that falls out of it.
+func (d *Decoder) advance(esi uint32) {
+	if d.started && esi <= d.highest {
+		return
+	}
+	d.started = true
+	d.highest = esi
+	base := d.base()
+	for k := range d.known {
+		if k < base {
+			delete(d.known, k)
+		}
+	}
+	d.eqs = slices.DeleteFunc(d.eqs, func(eq *equation) bool {
+		for k := range eq.coeffs {
+			if k < base {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AddSource adds a received source symbol.
+// Source symbols that are too old for the decoding window or already known are ignored.
+// Any source symbols that could be recovered as a result are returned, ordered by ESI.
+// The symbol is copied.
+func (d *Decoder) AddSource(esi uint32, symbol []byte) ([]Symbol, error) {
+	if len(symbol) != d.size {
+		return nil, ErrInvalidSize
+	}
+	d.advance(esi)
+	if _, ok := d.known[esi]; ok || esi < d.base() {
+		return nil, nil
+	}
+	data := slices.Clone(symbol)
+	d.known[esi] = data
+	changed := false
+	for _, eq := range d.eqs {
+		if c, ok := eq.coeffs[esi]; ok {
+			d.ll.GalMulSliceXor(c, data, eq.data)
+			delete(eq.coeffs, esi)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil, nil
+	}
+	return d.solve(), nil
+}
+
+// AddRepair adds a received repair symbol.
+// Repair symbols with an encoding window that starts before the decoding
+// window are ignored.
+// Any source symbols that could be recovered are returned, ordered by ESI.
+func (d *Decoder) AddRepair(id RepairID, symbol []byte) ([]Symbol, error) {
+	if len(symbol) != d.size {
+		return nil, ErrInvalidSize
+	}
+	if id.DT > maxDensity || id.NSS == 0 || id.NSS > MaxWindow {
+		return nil, ErrInvalidRepairID
+	}
+	d.advance(id.FirstESI + uint32(id.NSS) - 1)
+	if id.FirstESI < d.base() {
+		return nil, nil
+	}
+	eq := equation{coeffs: make(map[uint32]byte), data: slices.Clone(symbol)}
+	for i, c := range generateCoefficients(id.RepairKey, int(id.NSS), id.DT) {
+		if c == 0 {
+			continue
+		}
+		esi := id.FirstESI + uint32(i)
+		if src, ok := d.known[esi]; ok {
+			d.ll.GalMulSliceXor(c, src, eq.data)
+		} else {
+			eq.coeffs[esi] = c
+		}
+	}
+	if len(eq.coeffs) == 0 {
+		return nil, nil
+	}
+	d.eqs = append(d.eqs, &eq)
+	return d.solve(), nil
+}
+
+// Known returns the source symbol with the given ESI,
+// if it has been received or recovered and is still in the decoding window.
+func (d *Decoder) Known(esi uint32) ([]byte, bool) {
+	s, ok := d.known[esi]
+	return s, ok
+}
+
+// solve reduces the pending equations with Gauss-Jordan elimination
+// over the unknown source symbols, and returns the symbols that
+// could be recovered. The reduced equations are kept for later.
+func (d *Decoder) solve() []Symbol {
+	var unknowns []uint32
+	for _, eq := range d.eqs {
+		for esi := range eq.coeffs {
+			unknowns = append(unknowns, esi)
+		}
+	}
+	slices.Sort(unknowns)
+	unknowns = slices.Compact(unknowns)
+	col := make(map[uint32]int, len(unknowns))
+	for i, esi := range unknowns {
+		col[esi] = i
+	}
+
+	// Each row holds the coefficients followed by the data,
+	// so row operations are done in a single call.
+	n := len(unknowns)
+	rows := make([][]byte, len(d.eqs))
+	for i, eq := range d.eqs {
+		row := make([]byte, n+d.size)
+		for esi, c := range eq.coeffs {
+			row[col[esi]] = c
+		}
+		copy(row[n:], eq.data)
+		rows[i] = row
+	}
+
+	rank := 0
+	for c := 0; c < n && rank < len(rows); c++ {
+		p := slices.IndexFunc(rows[rank:], func(row []byte) bool { return row[c] != 0 })
+		if p < 0 {
+			continue
+		}
+		rows[rank], rows[rank+p] = rows[rank+p], rows[rank]
+		pivot := rows[rank]
+		if v := pivot[c]; v != 1 {
+			d.ll.GalMulSlice(reedsolomon.Inv(v), pivot, pivot)
+		}
+		for i, row := range rows {
+			if i != rank && row[c] != 0 {
+				d.ll.GalMulSliceXor(row[c], pivot, row)
+			}
+		}
+		rank++
+	}
+
+	// Rows with a single coefficient are solved.
+	// Rows without coefficients carry no information.
+	var solved []Symbol
+	d.eqs = d.eqs[:0]
+	for _, row := range rows[:rank] {
+		eq := equation{coeffs: make(map[uint32]byte)}
+		for i, c := range row[:n] {
+			if c != 0 {
+				eq.coeffs[unknowns[i]] = c
+			}
+		}
+		eq.data = row[n:]
+		if len(eq.coeffs) > 1 {
+			d.eqs = append(d.eqs, &eq)
+			continue
+		}
+		for esi := range eq.coeffs {
+			d.known[esi] = eq.data
+			solved = append(solved, Symbol{ESI: esi, Data: eq.data})
+		}
+	}
+	slices.SortFunc(solved, func(a, b Symbol) int { return cmp.Compare(a.ESI, b.ESI) })
+	return solved
+}
@@ -0,0 +1,202 @@
+// Package rlc implements sliding window random linear codes over GF(2^8),
+// as specified in RFC 8681.
+//
+// Unlike block codes, repair symbols are computed over a window of the most
+// recent source symbols, which moves forward as new source symbols are added.
+// Repair symbols can be sent at any time, so losses are repaired with a delay
+// bounded by the window size instead of waiting for a full block.
+//
+// The coding coefficients of each repair symbol are generated with the
+// TinyMT32 PRNG from RFC 8682, seeded with the repair key carried in the
+// repair FEC Payload ID, so they do not need to be transmitted.
+//
+// Source symbols all have the same size. Variable sized application data
+// must be framed and padded by the caller.
+package rlc
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+const (
+	// maxDensity is the density threshold where all coefficients are non-zero.
+	maxDensity = 15
+
+	// MaxWindow is the maximum number of source symbols in an encoding window,
+	// limited by the 12 bit NSS field of the repair payload ID.
+	MaxWindow = 1<<12 - 1
+
+	// RepairIDSize is the size of an encoded repair FEC Payload ID.
+	RepairIDSize = 8
+)
+
+var (
+	// ErrInvalidSize is returned if a symbol has the wrong size,
+	// or the symbol size or window size is invalid.
+	ErrInvalidSize = errors.New("rlc: invalid size")
+
+	// ErrEmptyWindow is returned if a repair symbol is requested
+	// before any source symbols have been added.
+	ErrEmptyWindow = errors.New("rlc: encoding window is empty")
+
+	// ErrInvalidRepairID is returned if a repair payload ID is invalid.
+	ErrInvalidRepairID = errors.New("rlc: invalid repair payload ID")
+
+	// ErrShortBuffer is returned if an encoded payload ID is truncated.
+	ErrShortBuffer = errors.New("rlc: buffer too short")
+)
+
+// RepairID is the repair FEC Payload ID from RFC 8681.
+type RepairID struct {
+	// RepairKey seeds the generation of the coding coefficients.
+	RepairKey uint16
+	// DT is the density threshold of the coefficients, 0 to 15.
+	DT uint8
+	// NSS is the number of source symbols in the encoding window.
+	NSS uint16
+	// FirstESI is the ESI of the first source symbol in the encoding window.
+	FirstESI uint32
+}
+
+// MarshalBinary returns the payload ID in wire format:
+//
+//	Repair_Key (16 bits) | DT (4 bits) | NSS (12 bits) | FirstESI (32 bits)
+func (id RepairID) MarshalBinary() ([]byte, error) {
+	if id.DT > maxDensity || id.NSS == 0 || id.NSS > MaxWindow {
+		return nil, ErrInvalidRepairID
+	}
+	dst := binary.BigEndian.AppendUint16(make([]byte, 0, RepairIDSize), id.RepairKey)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(id.DT)<<12|id.NSS)
+	return binary.BigEndian.AppendUint32(dst, id.FirstESI), nil
+}
+
+// UnmarshalBinary reads a payload ID in the format written by MarshalBinary.
+func (id *RepairID) UnmarshalBinary(b []byte) error {
+	if len(b) < RepairIDSize {
+		return ErrShortBuffer
+	}
+	v := binary.BigEndian.Uint16(b[2:])
+	r := RepairID{
+		RepairKey: binary.BigEndian.Uint16(b),
+		DT:        uint8(v >> 12),
+		NSS:       v & MaxWindow,
+		FirstESI:  binary.BigEndian.Uint32(b[4:]),
+	}
+	if r.NSS == 0 {
+		return ErrInvalidRepairID
+	}
+	*id = r
+	return nil
+}
+
+// Option allows to override processing parameters.
+type Option func(*options)
+
+type options struct {
+	density uint8
+}
+
+// WithDensity sets the density threshold (DT) of the coding coefficients.
+// Each coefficient is non-zero with probability (dt+1)/16.
+// Lower values make encoding and decoding faster, but make repair
+// symbols less likely to be useful. The default is 15,
+// where all coefficients are non-zero. Values above 15 are ignored.
+// Ignored by the decoder, which uses the value from the repair payload ID.
+func WithDensity(dt uint8) Option {
+	return func(o *options) {
+		if dt <= maxDensity {
+			o.density = dt
+		}
+	}
+}
+
+// Encoder keeps an encoding window of the most recent source symbols
+// and creates repair symbols over it.
+type Encoder struct {
+	o      options
+	size   int
+	window int
+	// symbols holds the encoding window, oldest first.
+	symbols   [][]byte
+	firstESI  uint32
+	repairKey uint16
+	ll        reedsolomon.LowLevel
+}
+
+// NewEncoder returns an encoder for source symbols of symbolSize bytes,
+// with an encoding window of at most window source symbols.
+func NewEncoder(symbolSize, window int, opts ...Option) (*Encoder, error) {
+	if symbolSize <= 0 || window <= 0 || window > MaxWindow {
+		return nil, ErrInvalidSize
+	}
+	e := Encoder{
+		o:       options{density: maxDensity},
+		size:    symbolSize,
+		window:  window,
+		symbols: make([][]byte, 0, window),
+	}
+	for _, opt := range opts {
+		opt(&e.o)
+	}
+	return &e, nil
+}
+
+// Add adds a source symbol to the encoding window and returns its ESI.
+// If the window is full, the oldest source symbol is removed.
+// The symbol is copied.
+func (e *Encoder) Add(symbol []byte) (esi uint32, err error) {
+	if len(symbol) != e.size {
+		return 0, ErrInvalidSize
+	}
+	var buf []byte
+	if len(e.symbols) == e.window {
+		buf = e.symbols[0]
+		copy(e.symbols, e.symbols[1:])
+		e.symbols = e.symbols[:len(e.symbols)-1]
+		e.firstESI++
+	} else {
+		buf = make([]byte, e.size)
+	}
+	copy(buf, symbol)
+	e.symbols = append(e.symbols, buf)
+	return e.firstESI + uint32(len(e.symbols)) - 1, nil
+}
+
+// Remove removes source symbols with an ESI lower than esi from the
+// encoding window. This can be used to stop protecting symbols that
+// are known to be received or that are no longer useful.
+func (e *Encoder) Remove(esi uint32) {
+	if esi <= e.firstESI {
+		return
+	}
+	n := min(int(esi-e.firstESI), len(e.symbols))
+	e.symbols = append(e.symbols[:0], e.symbols[n:]...)
+	e.firstESI += uint32(n)
+}
+
+// Repair returns a repair symbol over the current encoding window,
+// together with its payload ID. The repair key is incremented for
+// each repair symbol.
+func (e *Encoder) Repair() (RepairID, []byte, error) {
+	if len(e.symbols) == 0 {
+		return RepairID{}, nil, ErrEmptyWindow
+	}
+	id := RepairID{
+		RepairKey: e.repairKey,
+		DT:        e.o.density,
+		NSS:       uint16(len(e.symbols)),
+		FirstESI:  e.firstESI,
+	}
+	e.repairKey++
+	cc := generateCoefficients(id.RepairKey, len(e.symbols), id.DT)
+	repair := make([]byte, e.size)
+	for i, c := range cc {
+		if c != 0 {
+			e.ll.GalMulSliceXor(c, e.symbols[i], repair)
+		}
+	}
+	return id, repair, nil
+}
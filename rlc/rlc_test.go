@@ -0,0 +1,193 @@
+package rlc
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestTinyMT32(t *testing.T) {
+	// Reference output of TinyMT32 with seed 1.
+	want := []uint32{
+		2545341989, 981918433, 3715302833, 2387538352, 3591001365,
+		3820442102, 2114400566, 2196103051, 2783359912, 764534509,
+	}
+	s := newTinyMT32(1)
+	for i, w := range want {
+		if got := s.uint32(); got != w {
+			t.Fatalf("output %d: got %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestGenerateCoefficients(t *testing.T) {
+	cc := generateCoefficients(1234, 1000, 15)
+	for i, c := range cc {
+		if c == 0 {
+			t.Fatalf("coefficient %d is zero", i)
+		}
+	}
+	if !bytes.Equal(cc, generateCoefficients(1234, 1000, 15)) {
+		t.Fatal("coefficients not deterministic")
+	}
+	for _, dt := range []uint8{0, 7} {
+		nonZero := 0
+		for _, c := range generateCoefficients(1, 16000, dt) {
+			if c != 0 {
+				nonZero++
+			}
+		}
+		want := 1000 * (int(dt) + 1)
+		if nonZero < want*9/10 || nonZero > want*11/10 {
+			t.Errorf("dt %d: %d non-zero coefficients, expected about %d", dt, nonZero, want)
+		}
+	}
+}
+
+func TestRepairID(t *testing.T) {
+	id := RepairID{RepairKey: 0x1234, DT: 7, NSS: 0x567, FirstESI: 0x89abcdef}
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, []byte{0x12, 0x34, 0x75, 0x67, 0x89, 0xab, 0xcd, 0xef}) {
+		t.Fatalf("got %x", b)
+	}
+	var got RepairID
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Fatalf("got %+v, want %+v", got, id)
+	}
+	if err := got.UnmarshalBinary(b[:7]); err != ErrShortBuffer {
+		t.Errorf("expected %v, got %v", ErrShortBuffer, err)
+	}
+	if _, err := (RepairID{DT: 16, NSS: 1}).MarshalBinary(); err != ErrInvalidRepairID {
+		t.Errorf("expected %v, got %v", ErrInvalidRepairID, err)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// Sparse coefficients leave more losses uncovered.
+	for _, test := range []struct {
+		dt         uint8
+		maxMissing int // percent of lost symbols
+	}{{15, 5}, {4, 50}} {
+		dt := test.dt
+		rng := rand.New(rand.NewSource(0))
+		const size, window, total = 100, 20, 2000
+		enc, err := NewEncoder(size, window, WithDensity(dt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := NewDecoder(size, 2*window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := make([][]byte, total)
+		delivered := make([]bool, total)
+		deliver := func(syms []Symbol) {
+			for _, s := range syms {
+				if !bytes.Equal(s.Data, source[s.ESI]) {
+					t.Fatalf("dt %d: symbol %d recovered incorrectly", dt, s.ESI)
+				}
+				if delivered[s.ESI] {
+					t.Fatalf("dt %d: symbol %d delivered twice", dt, s.ESI)
+				}
+				delivered[s.ESI] = true
+			}
+		}
+		lost := 0
+		for i := range source {
+			source[i] = make([]byte, size)
+			rng.Read(source[i])
+			esi, err := enc.Add(source[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if esi != uint32(i) {
+				t.Fatalf("got esi %d, want %d", esi, i)
+			}
+			// Lose 10% of all packets.
+			if rng.Intn(10) > 0 {
+				delivered[i] = true
+				syms, err := dec.AddSource(esi, source[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				deliver(syms)
+			} else {
+				lost++
+			}
+			// Send one repair symbol for every four source symbols.
+			if i%4 == 3 && rng.Intn(10) > 0 {
+				id, repair, err := enc.Repair()
+				if err != nil {
+					t.Fatal(err)
+				}
+				syms, err := dec.AddRepair(id, repair)
+				if err != nil {
+					t.Fatal(err)
+				}
+				deliver(syms)
+			}
+			if len(dec.known) > 2*window {
+				t.Fatalf("decoder holds %d symbols", len(dec.known))
+			}
+		}
+		missing := 0
+		for _, ok := range delivered[:total-window] {
+			if !ok {
+				missing++
+			}
+		}
+		if missing*100 > lost*test.maxMissing {
+			t.Errorf("dt %d: %d of %d lost symbols not recovered", dt, missing, lost)
+		}
+	}
+}
+
+func TestEncoderRemove(t *testing.T) {
+	enc, err := NewEncoder(10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := enc.Repair(); err != ErrEmptyWindow {
+		t.Errorf("expected %v, got %v", ErrEmptyWindow, err)
+	}
+	for range 5 {
+		if _, err := enc.Add(make([]byte, 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	enc.Remove(3)
+	id, _, err := enc.Repair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.FirstESI != 3 || id.NSS != 2 {
+		t.Fatalf("got window %d+%d", id.FirstESI, id.NSS)
+	}
+	enc.Remove(100)
+	if esi, _ := enc.Add(make([]byte, 10)); esi != 5 {
+		t.Fatalf("got esi %d, want 5", esi)
+	}
+	if _, err := enc.Add(make([]byte, 9)); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+}
+
+func BenchmarkRepair(b *testing.B) {
+	enc, err := NewEncoder(1024, 64)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for range 64 {
+		_, _ = enc.Add(make([]byte, 1024))
+	}
+	b.SetBytes(64 * 1024)
+	for b.Loop() {
+		_, _, _ = enc.Repair()
+	}
+}
@@ -0,0 +1,90 @@
+package rlc
+
+// tinyMT32 is the TinyMT32 PRNG, with the parameters specified in RFC 8682.
+type tinyMT32 struct {
+	status [4]uint32
+}
+
+const (
+	tinyMTMat1 = 0x8f7011ee
+	tinyMTMat2 = 0xfc78ff1f
+	tinyMTTmat = 0x3793fdff
+
+	tinyMTMask = 0x7fffffff
+	tinyMTSh0  = 1
+	tinyMTSh1  = 10
+	tinyMTSh8  = 8
+
+	tinyMTMinLoop = 8
+	tinyMTPreLoop = 8
+)
+
+func newTinyMT32(seed uint32) *tinyMT32 {
+	s := tinyMT32{status: [4]uint32{seed, tinyMTMat1, tinyMTMat2, tinyMTTmat}}
+	for i := uint32(1); i < tinyMTMinLoop; i++ {
+		prev := s.status[(i-1)&3]
+		s.status[i&3] ^= i + 1812433253*(prev^(prev>>30))
+	}
+	// Period certification.
+	if s.status[0]&tinyMTMask == 0 && s.status[1] == 0 && s.status[2] == 0 && s.status[3] == 0 {
+		s.status = [4]uint32{'T', 'I', 'N', 'Y'}
+	}
+	for range tinyMTPreLoop {
+		s.nextState()
+	}
+	return &s
+}
+
+func (s *tinyMT32) nextState() {
+	y := s.status[3]
+	x := (s.status[0] & tinyMTMask) ^ s.status[1] ^ s.status[2]
+	x ^= x << tinyMTSh0
+	y ^= (y >> tinyMTSh0) ^ x
+	s.status[0] = s.status[1]
+	s.status[1] = s.status[2]
+	s.status[2] = x ^ (y << tinyMTSh1)
+	s.status[3] = y
+	mask := -(y & 1)
+	s.status[1] ^= mask & tinyMTMat1
+	s.status[2] ^= mask & tinyMTMat2
+}
+
+func (s *tinyMT32) temper() uint32 {
+	t0 := s.status[3]
+	t1 := s.status[0] + (s.status[2] >> tinyMTSh8)
+	t0 ^= t1
+	t0 ^= -(t1 & 1) & tinyMTTmat
+	return t0
+}
+
+func (s *tinyMT32) uint32() uint32 {
+	s.nextState()
+	return s.temper()
+}
+
+// rand16 returns a value in [0, 16).
+func (s *tinyMT32) rand16() uint32 {
+	return s.uint32() & 0xf
+}
+
+// rand256 returns a value in [0, 256).
+func (s *tinyMT32) rand256() uint32 {
+	return s.uint32() & 0xff
+}
+
+// generateCoefficients returns the coding coefficients of a repair symbol,
+// as specified in RFC 8681.
+// Each coefficient is non-zero with probability (dt+1)/16.
+func generateCoefficients(repairKey uint16, n int, dt uint8) []byte {
+	s := newTinyMT32(uint32(repairKey))
+	cc := make([]byte, n)
+	for i := range cc {
+		if dt != maxDensity && s.rand16() > uint32(dt) {
+			continue
+		}
+		for cc[i] == 0 {
+			cc[i] = byte(s.rand256())
+		}
+	}
+	return cc
+}
@@ -0,0 +1,143 @@
+package rlnc
+
+// Decoder recovers a generation from coded packets.
+//
+// Packets are reduced as they arrive, so the rank is always known and
+// the source packets are available as soon as the rank reaches the
+// generation size. Received packets are kept in reduced row echelon form,
+// meaning each stored row has a leading coefficient of one in a column
+// that is zero in all other rows.
+type Decoder struct {
+	fld  Field
+	f    field
+	k    int
+	size int
+	rank int
+	// pivots[i] is the row with its leading coefficient in column i, or nil.
+	pivots  [][]byte
+	scratch []byte
+}
+
+// NewDecoder returns a decoder for a generation of k source packets
+// with payloads of the given size.
+func NewDecoder(fld Field, k, size int) (*Decoder, error) {
+	f := fld.field()
+	if f == nil {
+		return nil, ErrInvalidField
+	}
+	if k <= 0 || k > maxGeneration || !f.validPayload(size) {
+		return nil, ErrInvalidSize
+	}
+	return &Decoder{fld: fld, f: f, k: k, size: size, pivots: make([][]byte, k)}, nil
+}
+
+// Add adds a coded packet to the decoder and returns the resulting rank.
+// The rank is unchanged if the packet was not linearly independent
+// of the packets already received.
+// The packet is copied and can be reused after the call.
+func (d *Decoder) Add(p *Packet) (rank int, err error) {
+	if p.Field != d.fld || len(p.Coefficients) != d.k || len(p.Payload) != d.size {
+		return d.rank, ErrMismatch
+	}
+	if d.fld == GF8 {
+		for _, c := range p.Coefficients {
+			if c > 255 {
+				return d.rank, ErrMismatch
+			}
+		}
+	}
+	if d.Complete() {
+		return d.rank, nil
+	}
+	f := d.f
+	row := p.row(f)
+
+	// Eliminate the known pivot columns.
+	for i, pivot := range d.pivots {
+		if pivot == nil {
+			continue
+		}
+		if c := f.get(row, i); c != 0 {
+			f.mulSliceXor(c, pivot, row)
+		}
+	}
+	col := -1
+	for i := range d.k {
+		if f.get(row, i) != 0 {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		// Not innovative.
+		return d.rank, nil
+	}
+
+	// Normalize the leading coefficient.
+	if c := f.get(row, col); c != 1 {
+		if len(d.scratch) != len(row) {
+			d.scratch = make([]byte, len(row))
+		}
+		clear(d.scratch)
+		f.mulSliceXor(f.inv(c), row, d.scratch)
+		row, d.scratch = d.scratch, row
+	}
+
+	// Remove the new column from the existing rows.
+	for _, pivot := range d.pivots {
+		if pivot == nil {
+			continue
+		}
+		if c := f.get(pivot, col); c != 0 {
+			f.mulSliceXor(c, row, pivot)
+		}
+	}
+	d.pivots[col] = row
+	d.rank++
+	return d.rank, nil
+}
+
+// Rank returns the number of linearly independent packets received.
+func (d *Decoder) Rank() int {
+	return d.rank
+}
+
+// Complete returns whether all source packets can be recovered.
+func (d *Decoder) Complete() bool {
+	return d.rank == d.k
+}
+
+// Data returns the source packets.
+// ErrNotComplete is returned if the decoder has not reached full rank.
+// The returned slices are owned by the decoder.
+func (d *Decoder) Data() ([][]byte, error) {
+	if !d.Complete() {
+		return nil, ErrNotComplete
+	}
+	n := d.f.vecLen(d.k)
+	data := make([][]byte, d.k)
+	for i, row := range d.pivots {
+		data[i] = row[n:]
+	}
+	return data, nil
+}
+
+// Recode returns a random combination of the packets received so far,
+// which can be forwarded to other nodes.
+// ErrNoPackets is returned if no innovative packets have been received.
+func (d *Decoder) Recode() (*Packet, error) {
+	if d.rank == 0 {
+		return nil, ErrNoPackets
+	}
+	var out []byte
+	for _, row := range d.pivots {
+		if row == nil {
+			continue
+		}
+		if out == nil {
+			out = make([]byte, len(row))
+		}
+		d.f.mulSliceXor(d.f.random(), row, out)
+	}
+	return packetFromRow(d.fld, d.f, d.k, out), nil
+}
@@ -0,0 +1,98 @@
+package rlnc
+
+import (
+	"math/rand/v2"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// Field selects the finite field used for coding.
+type Field uint8
+
+const (
+	// GF8 codes over GF(2^8).
+	// Payloads can have any length and coefficients are one byte each.
+	GF8 Field = iota
+
+	// GF16 codes over GF(2^16), using the Leopard field and memory layout.
+	// Payloads must be a multiple of 64 bytes, where each 64 byte chunk holds
+	// the low bytes of 32 elements followed by their high bytes.
+	// The larger field makes non-innovative random combinations less likely.
+	GF16
+)
+
+// field implements the operations needed for coding.
+// Vectors are byte slices in the native layout of the field,
+// so coefficient vectors and payloads can be processed together.
+type field interface {
+	// vecLen returns the number of bytes needed to hold n elements.
+	vecLen(n int) int
+	get(v []byte, i int) uint16
+	set(v []byte, i int, x uint16)
+	inv(x uint16) uint16
+	// mulSliceXor sets out[i] ^= c * in[i]. Slices must have the same length.
+	mulSliceXor(c uint16, in, out []byte)
+	// random returns a random non-zero element.
+	random() uint16
+	validPayload(n int) bool
+}
+
+func (f Field) field() field {
+	switch f {
+	case GF8:
+		return gf8{}
+	case GF16:
+		return gf16{}
+	}
+	return nil
+}
+
+type gf8 struct {
+	ll reedsolomon.LowLevel
+}
+
+func (gf8) vecLen(n int) int              { return n }
+func (gf8) get(v []byte, i int) uint16    { return uint16(v[i]) }
+func (gf8) set(v []byte, i int, x uint16) { v[i] = byte(x) }
+func (gf8) inv(x uint16) uint16           { return uint16(reedsolomon.Inv(byte(x))) }
+func (gf8) random() uint16                { return uint16(1 + rand.IntN(255)) }
+func (gf8) validPayload(n int) bool       { return n > 0 }
+func (f gf8) mulSliceXor(c uint16, in, out []byte) {
+	f.ll.GalMulSliceXor(byte(c), in, out)
+}
+
+type gf16 struct {
+	ll reedsolomon.LowLevel
+}
+
+func (gf16) vecLen(n int) int { return (n + 31) / 32 * 64 }
+
+func (gf16) get(v []byte, i int) uint16 {
+	idx := i/32*64 + i%32
+	return uint16(v[idx]) | uint16(v[idx+32])<<8
+}
+
+func (gf16) set(v []byte, i int, x uint16) {
+	idx := i/32*64 + i%32
+	v[idx] = byte(x)
+	v[idx+32] = byte(x >> 8)
+}
+
+// inv returns x^(2^16-2), which is the inverse of x.
+func (f gf16) inv(x uint16) uint16 {
+	res := uint16(1)
+	for e := 65534; e > 0; e >>= 1 {
+		if e&1 != 0 {
+			res = f.ll.GF16Mul(res, x)
+		}
+		x = f.ll.GF16Mul(x, x)
+	}
+	return res
+}
+
+func (gf16) random() uint16          { return uint16(1 + rand.IntN(65535)) }
+func (gf16) validPayload(n int) bool { return n > 0 && n%64 == 0 }
+
+func (f gf16) mulSliceXor(c uint16, in, out []byte) {
+	f.ll.GF16MulSliceXor(c, in, out)
+}
@@ -0,0 +1,234 @@
+// Package rlnc implements random linear network coding.
+//
+// A generation of k equally sized source packets is encoded into any number of
+// coded packets, each holding a random linear combination of the source packets.
+// The coefficients of the combination travel with the packet, so relays can
+// create new combinations of the packets they have received with Recode,
+// without decoding first. A Decoder recovers the generation once it has
+// received k linearly independent packets, no matter where they were created.
+//
+// The payload math uses the SIMD kernels of the reedsolomon package.
+package rlnc
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrInvalidField is returned if the field is unknown.
+	ErrInvalidField = errors.New("rlnc: invalid field")
+
+	// ErrInvalidSize is returned if the generation size or payload size is invalid.
+	ErrInvalidSize = errors.New("rlnc: invalid size")
+
+	// ErrMismatch is returned if packets do not belong to the same
+	// generation layout, meaning field, generation size and payload size differ.
+	ErrMismatch = errors.New("rlnc: packet does not match generation")
+
+	// ErrNoPackets is returned if Recode is called without packets.
+	ErrNoPackets = errors.New("rlnc: no packets")
+
+	// ErrShortPacket is returned if a marshaled packet is truncated.
+	ErrShortPacket = errors.New("rlnc: packet too short")
+
+	// ErrNotComplete is returned if data is requested before
+	// the decoder has reached full rank.
+	ErrNotComplete = errors.New("rlnc: decoding not complete")
+)
+
+// maxGeneration is the maximum number of source packets in a generation.
+const maxGeneration = 1<<16 - 1
+
+// headerSize is the size of the fixed part of a marshaled packet.
+const headerSize = 3
+
+// Packet is a coded packet.
+// Payload holds the linear combination of the source packets
+// given by Coefficients.
+type Packet struct {
+	Field        Field
+	Coefficients []uint16
+	Payload      []byte
+}
+
+// MarshalBinary returns the packet in wire format.
+//
+// The format is one byte with the field, the generation size as
+// 16 bit big endian, the coefficients, followed by the payload.
+// Coefficients are one byte each for GF8 and two bytes big endian for GF16.
+func (p *Packet) MarshalBinary() ([]byte, error) {
+	f := p.Field.field()
+	if f == nil {
+		return nil, ErrInvalidField
+	}
+	k := len(p.Coefficients)
+	if k == 0 || k > maxGeneration {
+		return nil, ErrInvalidSize
+	}
+	cs := coefficientSize(p.Field)
+	dst := make([]byte, headerSize, headerSize+k*cs+len(p.Payload))
+	dst[0] = byte(p.Field)
+	binary.BigEndian.PutUint16(dst[1:], uint16(k))
+	for _, c := range p.Coefficients {
+		if cs == 1 {
+			dst = append(dst, byte(c))
+		} else {
+			dst = binary.BigEndian.AppendUint16(dst, c)
+		}
+	}
+	return append(dst, p.Payload...), nil
+}
+
+// UnmarshalBinary reads a packet in the format written by MarshalBinary.
+// The payload will reference b.
+func (p *Packet) UnmarshalBinary(b []byte) error {
+	if len(b) < headerSize {
+		return ErrShortPacket
+	}
+	fld := Field(b[0])
+	if fld.field() == nil {
+		return ErrInvalidField
+	}
+	k := int(binary.BigEndian.Uint16(b[1:]))
+	if k == 0 {
+		return ErrInvalidSize
+	}
+	cs := coefficientSize(fld)
+	b = b[headerSize:]
+	if len(b) < k*cs {
+		return ErrShortPacket
+	}
+	coeffs := make([]uint16, k)
+	for i := range coeffs {
+		if cs == 1 {
+			coeffs[i] = uint16(b[i])
+		} else {
+			coeffs[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+	}
+	*p = Packet{Field: fld, Coefficients: coeffs, Payload: b[k*cs:]}
+	return nil
+}
+
+func coefficientSize(f Field) int {
+	if f == GF16 {
+		return 2
+	}
+	return 1
+}
+
+// row returns the packet as a single vector in field layout,
+// with the coefficients followed by the payload.
+func (p *Packet) row(f field) []byte {
+	n := f.vecLen(len(p.Coefficients))
+	row := make([]byte, n+len(p.Payload))
+	for i, c := range p.Coefficients {
+		f.set(row, i, c)
+	}
+	copy(row[n:], p.Payload)
+	return row
+}
+
+// packetFromRow converts a vector in field layout back to a packet.
+func packetFromRow(fld Field, f field, k int, row []byte) *Packet {
+	p := Packet{Field: fld, Coefficients: make([]uint16, k)}
+	for i := range p.Coefficients {
+		p.Coefficients[i] = f.get(row, i)
+	}
+	p.Payload = row[f.vecLen(k):]
+	return &p
+}
+
+// Encoder creates coded packets from a generation of source packets.
+type Encoder struct {
+	fld    Field
+	f      field
+	source [][]byte
+}
+
+// NewEncoder returns an encoder for the given source packets.
+// All source packets must have the same size, and the generation
+// can contain at most 65535 packets.
+// The source packets are referenced, not copied.
+func NewEncoder(fld Field, source [][]byte) (*Encoder, error) {
+	f := fld.field()
+	if f == nil {
+		return nil, ErrInvalidField
+	}
+	if len(source) == 0 || len(source) > maxGeneration {
+		return nil, ErrInvalidSize
+	}
+	size := len(source[0])
+	if !f.validPayload(size) {
+		return nil, ErrInvalidSize
+	}
+	for _, s := range source {
+		if len(s) != size {
+			return nil, ErrInvalidSize
+		}
+	}
+	return &Encoder{fld: fld, f: f, source: source}, nil
+}
+
+// Encode returns a packet with the combination of
+// the source packets given by coeffs.
+// A unit vector as coefficients gives an uncoded (systematic) packet.
+func (e *Encoder) Encode(coeffs []uint16) (*Packet, error) {
+	if len(coeffs) != len(e.source) {
+		return nil, ErrInvalidSize
+	}
+	p := Packet{
+		Field:        e.fld,
+		Coefficients: append([]uint16(nil), coeffs...),
+		Payload:      make([]byte, len(e.source[0])),
+	}
+	for i, c := range coeffs {
+		if e.fld == GF8 && c > 255 {
+			return nil, ErrInvalidSize
+		}
+		e.f.mulSliceXor(c, e.source[i], p.Payload)
+	}
+	return &p, nil
+}
+
+// Random returns a packet with a random combination of the source packets.
+func (e *Encoder) Random() *Packet {
+	coeffs := make([]uint16, len(e.source))
+	for i := range coeffs {
+		coeffs[i] = e.f.random()
+	}
+	p, _ := e.Encode(coeffs)
+	return p
+}
+
+// Recode returns a random combination of the given coded packets.
+// The result is a valid coded packet for the same generation,
+// which can be forwarded without decoding.
+// All packets must use the same field, generation size and payload size.
+func Recode(packets []*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, ErrNoPackets
+	}
+	first := packets[0]
+	f := first.Field.field()
+	if f == nil {
+		return nil, ErrInvalidField
+	}
+	k := len(first.Coefficients)
+	if k == 0 || !f.validPayload(len(first.Payload)) {
+		return nil, ErrInvalidSize
+	}
+	var out []byte
+	for _, p := range packets {
+		if p.Field != first.Field || len(p.Coefficients) != k || len(p.Payload) != len(first.Payload) {
+			return nil, ErrMismatch
+		}
+		row := p.row(f)
+		if out == nil {
+			out = make([]byte, len(row))
+		}
+		f.mulSliceXor(f.random(), row, out)
+	}
+	return packetFromRow(first.Field, f, k, out), nil
+}
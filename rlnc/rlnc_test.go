@@ -0,0 +1,257 @@
+package rlnc
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func testSource(rng *rand.Rand, k, size int) [][]byte {
+	source := make([][]byte, k)
+	for i := range source {
+		source[i] = make([]byte, size)
+		rng.Read(source[i])
+	}
+	return source
+}
+
+func checkData(t *testing.T, d *Decoder, source [][]byte) {
+	t.Helper()
+	data, err := d.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range source {
+		if !bytes.Equal(data[i], source[i]) {
+			t.Fatalf("packet %d mismatch", i)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, test := range []struct {
+		fld     Field
+		k, size int
+	}{
+		{GF8, 1, 1},
+		{GF8, 10, 100},
+		{GF8, 50, 1000},
+		{GF16, 1, 64},
+		{GF16, 40, 640},
+	} {
+		source := testSource(rng, test.k, test.size)
+		enc, err := NewEncoder(test.fld, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := NewDecoder(test.fld, test.k, test.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Start with some systematic packets.
+		for i := 0; i < test.k; i += 3 {
+			unit := make([]uint16, test.k)
+			unit[i] = 1
+			p, err := enc.Encode(unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.Payload, source[i]) {
+				t.Fatalf("systematic packet %d mismatch", i)
+			}
+			if _, err := d.Add(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sent := 0
+		for !d.Complete() {
+			before := d.Rank()
+			rank, err := d.Add(enc.Random())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rank < before || rank > before+1 || rank != d.Rank() {
+				t.Fatalf("rank went from %d to %d", before, rank)
+			}
+			sent++
+			if sent > 2*test.k+10 {
+				t.Fatalf("field %d, k=%d: not complete after %d packets", test.fld, test.k, sent)
+			}
+		}
+		checkData(t, d, source)
+	}
+}
+
+func TestNotInnovative(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	source := testSource(rng, 5, 100)
+	enc, err := NewEncoder(GF8, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDecoder(GF8, 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []*Packet
+	for range 3 {
+		p := enc.Random()
+		received = append(received, p)
+		if _, err := d.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d.Rank() != 3 {
+		t.Fatalf("expected rank 3, got %d", d.Rank())
+	}
+	// Combinations of received packets carry no new information.
+	for range 10 {
+		p, err := Recode(received)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rank, err := d.Add(p); err != nil || rank != 3 {
+			t.Fatalf("expected rank 3, got %d, %v", rank, err)
+		}
+	}
+	if _, err := d.Data(); err != ErrNotComplete {
+		t.Errorf("expected %v, got %v", ErrNotComplete, err)
+	}
+}
+
+func TestRecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, fld := range []Field{GF8, GF16} {
+		const k, size = 20, 256
+		source := testSource(rng, k, size)
+		enc, err := NewEncoder(fld, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Two relays each receive a part of the coded packets.
+		relay1, _ := NewDecoder(fld, k, size)
+		var relay2 []*Packet
+		for i := range 2 * k {
+			p := enc.Random()
+			if i%2 == 0 {
+				if _, err := relay1.Add(p); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				relay2 = append(relay2, p)
+			}
+		}
+		// The sink only receives recoded packets.
+		sink, _ := NewDecoder(fld, k, size)
+		for i := 0; !sink.Complete(); i++ {
+			var p *Packet
+			if i%2 == 0 {
+				p, err = relay1.Recode()
+			} else {
+				p, err = Recode(relay2)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sink.Add(p); err != nil {
+				t.Fatal(err)
+			}
+			if i > 4*k {
+				t.Fatalf("field %d: not complete after %d packets", fld, i)
+			}
+		}
+		checkData(t, sink, source)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, fld := range []Field{GF8, GF16} {
+		enc, err := NewEncoder(fld, testSource(rng, 7, 128))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := enc.Random()
+		b, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := headerSize + 7*coefficientSize(fld) + 128; len(b) != want {
+			t.Fatalf("expected %d bytes, got %d", want, len(b))
+		}
+		var got Packet
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if got.Field != p.Field || !bytes.Equal(got.Payload, p.Payload) {
+			t.Fatal("packet mismatch")
+		}
+		for i := range p.Coefficients {
+			if got.Coefficients[i] != p.Coefficients[i] {
+				t.Fatalf("coefficient %d mismatch", i)
+			}
+		}
+		if err := got.UnmarshalBinary(b[:headerSize+6]); err != ErrShortPacket {
+			t.Errorf("expected %v, got %v", ErrShortPacket, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewEncoder(GF16, [][]byte{make([]byte, 100)}); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+	if _, err := NewEncoder(GF8, [][]byte{make([]byte, 10), make([]byte, 11)}); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+	if _, err := NewEncoder(Field(2), [][]byte{make([]byte, 10)}); err != ErrInvalidField {
+		t.Errorf("expected %v, got %v", ErrInvalidField, err)
+	}
+	if _, err := NewDecoder(GF8, 0, 10); err != ErrInvalidSize {
+		t.Errorf("expected %v, got %v", ErrInvalidSize, err)
+	}
+	if _, err := Recode(nil); err != ErrNoPackets {
+		t.Errorf("expected %v, got %v", ErrNoPackets, err)
+	}
+	d, _ := NewDecoder(GF8, 2, 10)
+	if _, err := d.Add(&Packet{Field: GF8, Coefficients: []uint16{1}, Payload: make([]byte, 10)}); err != ErrMismatch {
+		t.Errorf("expected %v, got %v", ErrMismatch, err)
+	}
+	if _, err := d.Add(&Packet{Field: GF8, Coefficients: []uint16{1, 256}, Payload: make([]byte, 10)}); err != ErrMismatch {
+		t.Errorf("expected %v, got %v", ErrMismatch, err)
+	}
+	a := &Packet{Field: GF8, Coefficients: []uint16{1, 2}, Payload: make([]byte, 10)}
+	b := &Packet{Field: GF8, Coefficients: []uint16{1, 2}, Payload: make([]byte, 11)}
+	if _, err := Recode([]*Packet{a, b}); err != ErrMismatch {
+		t.Errorf("expected %v, got %v", ErrMismatch, err)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, fld := range []Field{GF8, GF16} {
+		const k, size = 32, 1024
+		enc, err := NewEncoder(fld, testSource(rand.New(rand.NewSource(0)), k, size))
+		if err != nil {
+			b.Fatal(err)
+		}
+		packets := make([]*Packet, 2*k)
+		for i := range packets {
+			packets[i] = enc.Random()
+		}
+		b.Run([]string{"GF8", "GF16"}[fld], func(b *testing.B) {
+			b.SetBytes(k * size)
+			for b.Loop() {
+				d, _ := NewDecoder(fld, k, size)
+				for _, p := range packets {
+					if _, err := d.Add(p); err != nil {
+						b.Fatal(err)
+					}
+					if d.Complete() {
+						break
+					}
+				}
+			}
+		})
+	}
+}
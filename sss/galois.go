@@ -0,0 +1,52 @@
+package sss
+
+import "encoding/binary"
+
+// Low byte of the field polynomial, x^8 + x^4 + x^3 + x^2 + 1.
+const polyLow = 0x1d
+
+// mul multiplies two field elements in constant time.
+func mul(a, b byte) byte {
+	var res byte
+	for range 8 {
+		// Add a if the low bit of b is set.
+		res ^= a & -(b & 1)
+		b >>= 1
+		// Multiply a by x.
+		a = (a << 1) ^ (polyLow & -(a >> 7))
+	}
+	return res
+}
+
+// mulSliceXor multiplies the elements of in by c, and adds the result to out:
+// out[i] ^= c * in[i].
+// c is considered public, so only the contents of in and out are processed
+// in constant time. 8 bytes are processed per iteration.
+func mulSliceXor(c byte, in, out []byte) {
+	out = out[:len(in)]
+	for len(in) >= 8 {
+		v := binary.LittleEndian.Uint64(in)
+		binary.LittleEndian.PutUint64(out, binary.LittleEndian.Uint64(out)^mul64(c, v))
+		in, out = in[8:], out[8:]
+	}
+	for i, v := range in {
+		out[i] ^= mul(c, v)
+	}
+}
+
+// mul64 multiplies 8 packed field elements in v by c.
+func mul64(c byte, v uint64) uint64 {
+	const (
+		lowBits  = 0x7f7f7f7f7f7f7f7f
+		highBits = 0x8080808080808080
+	)
+	var res uint64
+	for ; c != 0; c >>= 1 {
+		if c&1 != 0 {
+			res ^= v
+		}
+		hi := (v & highBits) >> 7
+		v = ((v & lowBits) << 1) ^ (hi * polyLow)
+	}
+	return res
+}
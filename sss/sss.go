@@ -0,0 +1,124 @@
+// Package sss implements Shamir's Secret Sharing over GF(2^8).
+//
+// The field is the same as the one used by the reedsolomon package
+// (polynomial x^8 + x^4 + x^3 + x^2 + 1), but all arithmetic that touches
+// secret material is done in constant time without table lookups.
+// Only the public x-coordinates of the shares are processed with tables.
+//
+// Each share is one byte longer than the secret.
+// The first byte of a share is its x-coordinate, followed by one
+// evaluated polynomial byte per secret byte.
+package sss
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// ErrInvalidCount is returned if the number of shares is less than the threshold,
+// or more than 255 shares are requested.
+var ErrInvalidCount = errors.New("sss: share count must be between threshold and 255")
+
+// ErrInvalidThreshold is returned if the threshold is less than 2.
+var ErrInvalidThreshold = errors.New("sss: threshold must be at least 2")
+
+// ErrEmptySecret is returned when attempting to split an empty secret.
+var ErrEmptySecret = errors.New("sss: secret is empty")
+
+// ErrTooFewShares is returned by Combine if less than 2 shares are given.
+var ErrTooFewShares = errors.New("sss: too few shares")
+
+// ErrInvalidShare is returned by Combine if a share is malformed,
+// has a zero x-coordinate or shares differ in length.
+var ErrInvalidShare = errors.New("sss: invalid share")
+
+// ErrDuplicateShare is returned by Combine if two shares have the same x-coordinate.
+var ErrDuplicateShare = errors.New("sss: duplicate share")
+
+// Split the secret into n shares, where any t of them can be combined
+// to recover the secret. Fewer than t shares reveal nothing about the secret.
+//
+// Each byte of the secret is the constant term of its own random
+// polynomial of degree t-1. Share i (1 <= i <= n) contains the
+// x-coordinate i followed by the evaluation of each polynomial at i.
+//
+// Random coefficients are read from crypto/rand.
+func Split(secret []byte, n, t int) ([][]byte, error) {
+	if t < 2 {
+		return nil, ErrInvalidThreshold
+	}
+	if n < t || n > 255 {
+		return nil, ErrInvalidCount
+	}
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+
+	// Coefficients for x^1 to x^(t-1), one polynomial per secret byte.
+	coeffs := make([]byte, (t-1)*len(secret))
+	if _, err := io.ReadFull(rand.Reader, coeffs); err != nil {
+		return nil, err
+	}
+	defer clear(coeffs)
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		x := byte(i + 1)
+		share := make([]byte, len(secret)+1)
+		share[0] = x
+		y := share[1:]
+		copy(y, secret)
+		xPow := byte(1)
+		for j := 0; j < t-1; j++ {
+			xPow = mul(xPow, x)
+			mulSliceXor(xPow, coeffs[j*len(secret):(j+1)*len(secret)], y)
+		}
+		shares[i] = share
+	}
+	return shares, nil
+}
+
+// Combine the shares and return the secret.
+//
+// At least the threshold number of shares given to Split must be supplied,
+// otherwise the returned secret will be garbage. This cannot be detected.
+// Supplying more shares than the threshold is allowed.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrTooFewShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrInvalidShare
+	}
+	var seen [256]bool
+	for _, share := range shares {
+		if len(share) != size || share[0] == 0 {
+			return nil, ErrInvalidShare
+		}
+		if seen[share[0]] {
+			return nil, ErrDuplicateShare
+		}
+		seen[share[0]] = true
+	}
+
+	// Lagrange interpolation at x = 0.
+	// The basis values only depend on the public x-coordinates.
+	secret := make([]byte, size-1)
+	for i, si := range shares {
+		xi := si[0]
+		basis := byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := sj[0]
+			basis = mul(basis, mul(xj, reedsolomon.Inv(xj^xi)))
+		}
+		mulSliceXor(basis, si[1:], secret)
+	}
+	return secret, nil
+}
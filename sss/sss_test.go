@@ -0,0 +1,147 @@
+package sss
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+func TestMul(t *testing.T) {
+	var ll reedsolomon.LowLevel
+	in := make([]byte, 256)
+	for i := range in {
+		in[i] = byte(i)
+	}
+	want := make([]byte, 256)
+	got := make([]byte, 256)
+	for c := range 256 {
+		ll.GalMulSlice(byte(c), in, want)
+		for i := range in {
+			if v := mul(byte(c), in[i]); v != want[i] {
+				t.Fatalf("mul(%d, %d) = %d, want %d", c, i, v, want[i])
+			}
+		}
+		clear(got)
+		mulSliceXor(byte(c), in, got)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("mulSliceXor(%d) mismatch", c)
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, test := range []struct{ n, t, size int }{
+		{2, 2, 1},
+		{3, 2, 16},
+		{5, 3, 33},
+		{10, 7, 100},
+		{255, 2, 17},
+		{255, 255, 9},
+	} {
+		secret := make([]byte, test.size)
+		rng.Read(secret)
+		shares, err := Split(secret, test.n, test.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shares) != test.n {
+			t.Fatalf("got %d shares, want %d", len(shares), test.n)
+		}
+		for i, share := range shares {
+			if len(share) != test.size+1 || share[0] != byte(i+1) {
+				t.Fatalf("share %d: unexpected layout", i)
+			}
+		}
+		for range 10 {
+			// Pick a random subset of at least t shares.
+			k := test.t + rng.Intn(test.n-test.t+1)
+			sub := make([][]byte, 0, k)
+			for _, idx := range rng.Perm(test.n)[:k] {
+				sub = append(sub, shares[idx])
+			}
+			got, err := Combine(sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatalf("n=%d, t=%d, k=%d: secret mismatch", test.n, test.t, k)
+			}
+		}
+		// With one share less than the threshold we should (almost certainly) not recover.
+		if test.t > 2 && test.size > 8 {
+			got, err := Combine(shares[:test.t-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(got, secret) {
+				t.Fatalf("n=%d, t=%d: secret recovered with too few shares", test.n, test.t)
+			}
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	secret := []byte("secret")
+	if _, err := Split(secret, 5, 1); err != ErrInvalidThreshold {
+		t.Errorf("expected %v, got %v", ErrInvalidThreshold, err)
+	}
+	if _, err := Split(secret, 2, 3); err != ErrInvalidCount {
+		t.Errorf("expected %v, got %v", ErrInvalidCount, err)
+	}
+	if _, err := Split(secret, 256, 3); err != ErrInvalidCount {
+		t.Errorf("expected %v, got %v", ErrInvalidCount, err)
+	}
+	if _, err := Split(nil, 5, 3); err != ErrEmptySecret {
+		t.Errorf("expected %v, got %v", ErrEmptySecret, err)
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	shares, err := Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine(shares[:1]); err != ErrTooFewShares {
+		t.Errorf("expected %v, got %v", ErrTooFewShares, err)
+	}
+	if _, err := Combine([][]byte{shares[0], shares[1][:3]}); err != ErrInvalidShare {
+		t.Errorf("expected %v, got %v", ErrInvalidShare, err)
+	}
+	zero := append([]byte{}, shares[1]...)
+	zero[0] = 0
+	if _, err := Combine([][]byte{shares[0], zero}); err != ErrInvalidShare {
+		t.Errorf("expected %v, got %v", ErrInvalidShare, err)
+	}
+	if _, err := Combine([][]byte{shares[0], shares[1], shares[0]}); err != ErrDuplicateShare {
+		t.Errorf("expected %v, got %v", ErrDuplicateShare, err)
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	secret := make([]byte, 1<<10)
+	b.SetBytes(int64(len(secret)))
+	for b.Loop() {
+		_, err := Split(secret, 10, 5)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCombine(b *testing.B) {
+	secret := make([]byte, 1<<10)
+	shares, err := Split(secret, 10, 5)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(secret)))
+	for b.Loop() {
+		_, err := Combine(shares[:5])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
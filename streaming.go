@@ -8,10 +8,17 @@
 package reedsolomon
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // StreamEncoder is an interface to encode Reed-Salomon parity sets for your data.
@@ -43,6 +50,33 @@ type StreamEncoder interface {
 	// StreamWriteError will be returned.
 	Encode(data []io.Reader, parity []io.Writer) error
 
+	// EncodeFrom resumes encoding parity shards at offset,
+	// for example from a checkpoint reported by WithStreamCheckpoint.
+	//
+	// All data readers and parity writers are seeked to offset
+	// before encoding continues as with Encode.
+	// The parity writers must contain correct parity for the data before offset.
+	//
+	// If a data stream returns an error, a StreamReadError type error
+	// will be returned. If a parity writer returns an error, a
+	// StreamWriteError will be returned.
+	EncodeFrom(data []io.ReadSeeker, parity []io.WriteSeeker, offset int64) error
+
+	// Update parity shards for changed data shards.
+	//
+	// oldData and newData contain the old and new content of the changed
+	// data shards, indexed by data shard number. They must contain the same indexes.
+	// Unchanged data shards are not read.
+	// oldParity must contain readers for all parity shards,
+	// and the updated parity is written to newParity.
+	//
+	// Each reader must supply the same number of bytes.
+	//
+	// If a stream returns an error, a StreamReadError type error with the shard
+	// number of the stream will be returned. If a parity writer returns an error,
+	// a StreamWriteError with the index in newParity will be returned.
+	Update(oldData, newData map[int]io.Reader, oldParity []io.Reader, newParity []io.Writer) error
+
 	// Verify returns true if the parity shards contain correct data.
 	//
 	// The number of shards must match the number total data+parity shards
@@ -53,6 +87,21 @@ type StreamEncoder interface {
 	// will be returned.
 	Verify(shards []io.Reader) (bool, error)
 
+	// VerifyDetailed returns the blocks where the parity shards do not match the data.
+	// If no mismatches are found, nil is returned.
+	//
+	// The number of shards must match the number total data+parity shards
+	// given to NewStream().
+	// Each reader must supply the same number of bytes.
+	//
+	// If there are at least 2 parity shards, the shard that is likely corrupt is
+	// identified for each block, assuming only one shard of the block is corrupt.
+	// Repair can then be limited to the reported regions of that shard.
+	//
+	// If a shard stream returns an error, a StreamReadError type error
+	// will be returned.
+	VerifyDetailed(shards []io.Reader) ([]StreamMismatch, error)
+
 	// Reconstruct will recreate the missing shards if possible.
 	//
 	// Given a list of valid shards (to read) and invalid shards (to write)
@@ -69,6 +118,64 @@ type StreamEncoder interface {
 	// Use the Verify function to check if data set is ok.
 	Reconstruct(valid []io.Reader, fill []io.Writer) error
 
+	// ReconstructData will recreate the missing data shards if possible.
+	//
+	// This functions as Reconstruct, but only data shards are recreated,
+	// and fill writers for parity shards are ignored.
+	// Only DataShards of the valid shards are read, preferring data shards.
+	//
+	// If there are too few shards to reconstruct the missing
+	// ones, ErrTooFewShards will be returned.
+	ReconstructData(valid []io.Reader, fill []io.Writer) error
+
+	// ReconstructSome will recreate the shards with a non-nil fill writer if possible.
+	//
+	// This functions as Reconstruct, but only DataShards of the valid shards are read,
+	// preferring data shards, so parity shards that aren't needed are not read.
+	//
+	// If there are too few shards to reconstruct the missing
+	// ones, ErrTooFewShards will be returned.
+	ReconstructSome(valid []io.Reader, fill []io.Writer) error
+
+	// EncodeAt encodes parity shards for size bytes of data shards using positional I/O.
+	//
+	// The number of data and parity shards must match the number given to NewStream().
+	// Blocks are read, encoded and written in parallel.
+	// The number of blocks processed concurrently is set by WithStreamConcurrency,
+	// by default GOMAXPROCS up to a maximum of 4.
+	// Each block in flight uses a buffer of block size for all shards.
+	//
+	// If a data shard contains less than size bytes, ErrShortData will be returned.
+	// If a data stream returns an error, a StreamReadError type error
+	// will be returned. If a parity writer returns an error, a
+	// StreamWriteError will be returned.
+	EncodeAt(data []io.ReaderAt, parity []io.WriterAt, size int64) error
+
+	// VerifyAt returns true if the parity shards contain correct data,
+	// reading size bytes of all shards using positional I/O.
+	//
+	// The number of shards must match the number total data+parity shards
+	// given to NewStream().
+	// Blocks are read and verified in parallel, see EncodeAt.
+	//
+	// If a shard contains less than size bytes, ErrShortData will be returned.
+	// If a shard stream returns an error, a StreamReadError type error
+	// will be returned.
+	VerifyAt(shards []io.ReaderAt, size int64) (bool, error)
+
+	// ReconstructAt will recreate size bytes of the missing shards if possible,
+	// using positional I/O.
+	//
+	// You indicate that a shard is missing by setting it to nil in the 'valid'
+	// slice and at the same time setting a non-nil writer in "fill".
+	// An index cannot contain both non-nil 'valid' and 'fill' entry.
+	// If both are provided 'ErrReconstructMismatch' is returned.
+	// Blocks are read, reconstructed and written in parallel, see EncodeAt.
+	//
+	// If there are too few shards to reconstruct the missing
+	// ones, ErrTooFewShards will be returned.
+	ReconstructAt(valid []io.ReaderAt, fill []io.WriterAt, size int64) error
+
 	// Split a an input stream into the number of shards given to the encoder.
 	//
 	// The data will be split into equally sized shards.
@@ -88,6 +195,79 @@ type StreamEncoder interface {
 	// If there are to few shards given, ErrTooFewShards will be returned.
 	// If the total data size is less than outSize, ErrShortData will be returned.
 	Join(dst io.Writer, shards []io.Reader, outSize int64) error
+
+	// SplitEncode splits an input stream into data shards and
+	// encodes the parity shards, reading the input only once.
+	//
+	// The data shards are identical to the output of Split,
+	// and the parity shards are identical to the output of Encode.
+	// shards must contain TotalShards writers, data shards followed by parity shards.
+	//
+	// You must supply the total size of your input.
+	// 'ErrShortData' will be returned if it is unable to retrieve the
+	// number of bytes indicated.
+	//
+	// Each block of all data shards is read from its position in the input,
+	// parity is encoded in the same buffers, and all shards are written
+	// block by block, so only a single block of each shard is kept in memory.
+	// Use SplitEncodeStream for inputs that can only be read in order.
+	//
+	// If a shard writer returns an error, a StreamWriteError will be returned.
+	SplitEncode(data io.ReaderAt, size int64, shards []io.Writer) error
+
+	// SplitStream splits an input stream of unknown length into data shards,
+	// reading until io.EOF.
+	//
+	// The input is split into stripes of block size bytes per data shard.
+	// Each data shard receives a block of each stripe in turn.
+	// The final stripe is smaller and ends with an 8 byte trailer
+	// containing the total input size, so all shards will have the same size.
+	// The shards must be joined by JoinStream with the same block size.
+	// They can be encoded, verified and reconstructed as any other shards.
+	//
+	// The total size of the input is returned.
+	SplitStream(data io.Reader, dst []io.Writer) (size int64, err error)
+
+	// SplitEncodeStream splits an input stream of unknown length
+	// and encodes the parity shards, reading until io.EOF.
+	//
+	// The data shards are identical to the output of SplitStream,
+	// and the parity shards are identical to the output of Encode.
+	// shards must contain TotalShards writers, data shards followed by parity shards.
+	//
+	// The total size of the input is returned.
+	SplitEncodeStream(data io.Reader, shards []io.Writer) (size int64, err error)
+
+	// JoinStream joins data shards created by SplitStream or SplitEncodeStream
+	// and writes the original data to dst.
+	//
+	// Only the data shards are considered.
+	// The size of the output is read from the trailer, and padding is removed.
+	// The number of bytes written to dst is returned.
+	//
+	// If there are too few shards given, ErrTooFewShards will be returned.
+	// If the trailer does not match the data, ErrShortData will be returned.
+	JoinStream(dst io.Writer, shards []io.Reader) (int64, error)
+
+	// ReconstructJoin will join the data shards and write outSize bytes to dst,
+	// rebuilding missing data shards in memory as needed.
+	//
+	// shards must contain TotalShards entries, and the data shards must
+	// be created by Split with outSize as size.
+	// Missing shards are indicated by a nil reader.
+	// At most DataShards shards are read, preferring data shards.
+	//
+	// If no data shards are missing, this is equivalent to Join.
+	// Otherwise, if dst implements io.WriterAt, all shards are read in a single
+	// pass and each block is written at its position in the output.
+	// If dst does not implement io.WriterAt, the readers must implement io.ReaderAt,
+	// and blocks are read in output order.
+	// ErrNotSupported is returned if neither is the case.
+	//
+	// If there are too few shards to reconstruct the missing
+	// ones, ErrTooFewShards will be returned.
+	// If the shards contain less than outSize bytes, ErrShortData will be returned.
+	ReconstructJoin(dst io.Writer, shards []io.Reader, outSize int64) error
 }
 
 // StreamReadError is returned when a read error is encountered
@@ -126,6 +306,135 @@ func (s StreamWriteError) String() string {
 	return s.Error()
 }
 
+// StreamDropped describes a stream that was dropped because of a failure.
+// See WithStreamFailureTolerance.
+type StreamDropped struct {
+	Stream int   // The stream number that was dropped
+	Offset int64 // The offset in the stream of the first byte that could not be read
+	Err    error // The error returned by the stream, or io.ErrUnexpectedEOF if it ended early
+}
+
+// StreamProgress describes the progress of a stream operation.
+// See WithStreamProgress.
+type StreamProgress struct {
+	Bytes   int64         // The number of bytes read from all input streams
+	Blocks  int64         // The number of blocks processed
+	Elapsed time.Duration // The time since the operation started
+
+	// The total time spent reading from each input stream
+	// and writing to each output stream.
+	// The streams are indexed as given to the operation.
+	ReadLatency, WriteLatency []time.Duration
+}
+
+// StreamBlock is a block processed by a stream operation.
+// See WithStreamBlockHook.
+type StreamBlock struct {
+	Index  int64 // The block number within the operation
+	Offset int64 // The offset of the block within each shard
+
+	// Shards contains the block of each shard.
+	// Shards that are not part of the block have zero length.
+	// For Split and Join only a single shard is part of each block.
+	// The content is only valid until the hook returns.
+	Shards [][]byte
+}
+
+// streamStats collects progress of a stream operation.
+type streamStats struct {
+	fn     func(StreamProgress)
+	start  time.Time
+	blocks int64
+	bytes  atomic.Int64
+	reads  []atomic.Int64
+	writes []atomic.Int64
+}
+
+// newStats returns stats for an operation reading from in and writing to out,
+// and the streams wrapped to collect them.
+// If progress is not enabled, nil is returned with the unmodified streams.
+func (r *rsStream) newStats(in []io.Reader, out []io.Writer) (*streamStats, []io.Reader, []io.Writer) {
+	if r.o.progress == nil {
+		return nil, in, out
+	}
+	s := &streamStats{
+		fn:     r.o.progress,
+		start:  time.Now(),
+		reads:  make([]atomic.Int64, len(in)),
+		writes: make([]atomic.Int64, len(out)),
+	}
+	wrappedIn := make([]io.Reader, len(in))
+	for i := range in {
+		if in[i] != nil {
+			wrappedIn[i] = timedReader{r: in[i], t: &s.reads[i], n: &s.bytes}
+		}
+	}
+	wrappedOut := make([]io.Writer, len(out))
+	for i := range out {
+		if out[i] != nil {
+			wrappedOut[i] = timedWriter{w: out[i], t: &s.writes[i]}
+		}
+	}
+	return s, wrappedIn, wrappedOut
+}
+
+// block records that a block has been processed and reports the progress.
+func (s *streamStats) block() {
+	if s == nil {
+		return
+	}
+	s.blocks++
+	p := StreamProgress{
+		Bytes:        s.bytes.Load(),
+		Blocks:       s.blocks,
+		Elapsed:      time.Since(s.start),
+		ReadLatency:  make([]time.Duration, len(s.reads)),
+		WriteLatency: make([]time.Duration, len(s.writes)),
+	}
+	for i := range s.reads {
+		p.ReadLatency[i] = time.Duration(s.reads[i].Load())
+	}
+	for i := range s.writes {
+		p.WriteLatency[i] = time.Duration(s.writes[i].Load())
+	}
+	s.fn(p)
+}
+
+// timedReader records the time spent reading and the bytes read.
+type timedReader struct {
+	r    io.Reader
+	t, n *atomic.Int64
+}
+
+func (t timedReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.r.Read(p)
+	t.t.Add(int64(time.Since(start)))
+	t.n.Add(int64(n))
+	return n, err
+}
+
+// timedWriter records the time spent writing.
+type timedWriter struct {
+	w io.Writer
+	t *atomic.Int64
+}
+
+func (t timedWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.w.Write(p)
+	t.t.Add(int64(time.Since(start)))
+	return n, err
+}
+
+// blockHook calls the block hook, if any.
+func (r *rsStream) blockHook(index, offset int64, shards [][]byte) error {
+	if r.o.blockHook == nil {
+		return nil
+	}
+	return r.o.blockHook(StreamBlock{Index: index, Offset: offset, Shards: shards})
+}
+
 // rsStream contains a matrix for a specific
 // distribution of datashards and parity shards.
 // Construct if using NewStream()
@@ -161,6 +470,9 @@ func NewStream(dataShards, parityShards int, o ...Option) (StreamEncoder, error)
 	if r.o.streamBS <= 0 {
 		r.o.streamBS = 4 << 20
 	}
+	if r.o.streamConc <= 0 {
+		r.o.streamConc = min(runtime.GOMAXPROCS(0), 4)
+	}
 	if r.o.shardSize == 0 && r.o.maxGoroutines == defaultOptions.maxGoroutines {
 		o = append(o, WithAutoGoroutines(r.o.streamBS))
 	}
@@ -225,36 +537,219 @@ func (r *rsStream) Encode(data []io.Reader, parity []io.Writer) error {
 	if len(parity) != r.r.parityShards {
 		return ErrTooFewShards
 	}
+	return r.encode(data, parity, 0)
+}
+
+// EncodeFrom resumes encoding parity shards at offset,
+// for example from a checkpoint reported by WithStreamCheckpoint.
+//
+// All data readers and parity writers are seeked to offset
+// before encoding continues as with Encode.
+// The parity writers must contain correct parity for the data before offset.
+//
+// If a data stream returns an error, a StreamReadError type error
+// will be returned. If a parity writer returns an error, a
+// StreamWriteError will be returned.
+func (r *rsStream) EncodeFrom(data []io.ReadSeeker, parity []io.WriteSeeker, offset int64) error {
+	if len(data) != r.r.dataShards {
+		return ErrTooFewShards
+	}
+	if len(parity) != r.r.parityShards {
+		return ErrTooFewShards
+	}
+	if offset < 0 {
+		return ErrInvalidInput
+	}
+	readers := make([]io.Reader, len(data))
+	for i, d := range data {
+		if d == nil {
+			return StreamReadError{Err: ErrShardNoData, Stream: i}
+		}
+		if _, err := d.Seek(offset, io.SeekStart); err != nil {
+			return StreamReadError{Err: err, Stream: i}
+		}
+		readers[i] = d
+	}
+	writers := make([]io.Writer, len(parity))
+	for i, p := range parity {
+		if p == nil {
+			return StreamWriteError{Err: ErrShardNoData, Stream: i}
+		}
+		if _, err := p.Seek(offset, io.SeekStart); err != nil {
+			return StreamWriteError{Err: err, Stream: i}
+		}
+		writers[i] = p
+	}
+	return r.encode(readers, writers, offset)
+}
+
+// Update parity shards for changed data shards.
+//
+// oldData and newData contain the old and new content of the changed
+// data shards, indexed by data shard number. They must contain the same indexes.
+// Unchanged data shards are not read.
+// oldParity must contain readers for all parity shards,
+// and the updated parity is written to newParity.
+//
+// Each reader must supply the same number of bytes.
+//
+// If a stream returns an error, a StreamReadError type error with the shard
+// number of the stream will be returned. If a parity writer returns an error,
+// a StreamWriteError with the index in newParity will be returned.
+func (r *rsStream) Update(oldData, newData map[int]io.Reader, oldParity []io.Reader, newParity []io.Writer) error {
+	if len(oldParity) != r.r.parityShards || len(newParity) != r.r.parityShards {
+		return ErrTooFewShards
+	}
+	if len(oldData) != len(newData) {
+		return ErrInvalidInput
+	}
+	changed := slices.Sorted(maps.Keys(newData))
+	for _, idx := range changed {
+		if idx < 0 || idx >= r.r.dataShards {
+			return ErrInvShardNum
+		}
+		if oldData[idx] == nil || newData[idx] == nil {
+			return ErrInvalidInput
+		}
+	}
+
+	// Read old and new data of changed shards followed by the old parity.
+	var readers []io.Reader
+	var streams []int
+	for _, idx := range changed {
+		readers = append(readers, oldData[idx], newData[idx])
+		streams = append(streams, idx, idx)
+	}
+	for i, p := range oldParity {
+		if p == nil {
+			return StreamReadError{Err: ErrShardNoData, Stream: r.r.dataShards + i}
+		}
+		readers = append(readers, p)
+		streams = append(streams, r.r.dataShards+i)
+	}
+	bufs := AllocAligned(len(readers), r.o.streamBS)
+	oldIn := make([][]byte, r.r.dataShards)
+	newIn := make([][]byte, r.r.dataShards)
+	read := 0
+	for {
+		for i := range bufs {
+			bufs[i] = bufs[i][:r.o.streamBS]
+		}
+		err := r.readShards(bufs, readers)
+		if se, ok := err.(StreamReadError); ok {
+			se.Stream = streams[se.Stream]
+			err = se
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			if read == 0 {
+				return ErrShardNoData
+			}
+			return nil
+		default:
+			return err
+		}
+		size := shardSize(bufs)
+		read += size
+		for i, idx := range changed {
+			oldIn[idx], newIn[idx] = bufs[2*i], bufs[2*i+1]
+		}
+		parity := bufs[2*len(changed):]
+		r.r.updateParityShards(r.r.parity, oldIn, newIn, parity, r.r.parityShards, size)
+		if err := r.writeShards(newParity, parity); err != nil {
+			return err
+		}
+	}
+}
+
+// encode encodes the parity of the data,
+// which is positioned at offset in the shards.
+func (r *rsStream) encode(data []io.Reader, parity []io.Writer, offset int64) error {
+	if r.o.pipeline > 1 {
+		return r.encodePipelined(data, parity, offset)
+	}
 
 	all := r.createSlice()
 	defer r.blockPool.Put(all)
 	in := all[:r.r.dataShards]
 	out := all[r.r.dataShards:]
 	read := 0
+	cp := r.newCheckpointer(offset)
+	stats, data, parity := r.newStats(data, parity)
 
-	for {
+	for block := int64(0); ; block++ {
 		err := r.readShards(in, data)
 		switch err {
 		case nil:
 		case io.EOF:
-			if read == 0 {
+			if read == 0 && offset == 0 {
 				return ErrShardNoData
 			}
+			cp.finish()
 			return nil
 		default:
 			return err
 		}
 		out = trimShards(out, shardSize(in))
-		read += shardSize(in)
 		err = r.r.Encode(all)
 		if err != nil {
 			return err
 		}
+		if err := r.blockHook(block, offset+int64(read), all); err != nil {
+			return err
+		}
+		read += shardSize(in)
 		err = r.writeShards(parity, out)
 		if err != nil {
 			return err
 		}
+		cp.written(shardSize(in))
+		stats.block()
+	}
+}
+
+// StreamCheckpoint describes how far stream encoding has progressed.
+// See WithStreamCheckpoint.
+type StreamCheckpoint struct {
+	Offset int64 // The number of bytes of each shard that have been encoded and written
+	Blocks int64 // The number of blocks that have been encoded and written
+}
+
+// checkpointer reports checkpoints while encoding.
+type checkpointer struct {
+	fn      func(StreamCheckpoint)
+	every   int
+	pending int
+	cp      StreamCheckpoint
+}
+
+// newCheckpointer returns a checkpointer for encoding starting at offset.
+func (r *rsStream) newCheckpointer(offset int64) *checkpointer {
+	bs := int64(r.o.streamBS)
+	return &checkpointer{
+		fn:    r.o.checkpoint,
+		every: r.o.checkpointBlocks,
+		cp:    StreamCheckpoint{Offset: offset, Blocks: (offset + bs - 1) / bs},
+	}
+}
+
+// written records that a block of n bytes per shard has been written.
+func (c *checkpointer) written(n int) {
+	c.cp.Offset += int64(n)
+	c.cp.Blocks++
+	c.pending++
+	if c.pending >= c.every {
+		c.finish()
+	}
+}
+
+// finish reports the current checkpoint if it hasn't been reported.
+func (c *checkpointer) finish() {
+	if c.fn != nil && c.pending > 0 {
+		c.fn(c.cp)
 	}
+	c.pending = 0
 }
 
 // Trim the shards so they are all the same size
@@ -325,6 +820,94 @@ func writeShards(out []io.Writer, in [][]byte) error {
 	return nil
 }
 
+// readBlock reads a block from all non-nil readers in 'in'.
+// offset is the number of bytes read from each stream before this block.
+//
+// If failure tolerance is enabled, streams that fail or end before the others
+// are dropped by setting them to nil in 'in' and reported.
+// An error is only returned if fewer than DataShards streams remain.
+func (r *rsStream) readBlock(dst [][]byte, in []io.Reader, offset int64) error {
+	if !r.o.dropStreams {
+		return r.readShards(dst, in)
+	}
+	if len(in) != len(dst) {
+		panic("internal error: in and dst size do not match")
+	}
+	sizes := make([]int, len(in))
+	errs := make([]error, len(in))
+	read := func(i int) {
+		sizes[i], errs[i] = io.ReadFull(in[i], dst[i])
+		if errs[i] == io.EOF || errs[i] == io.ErrUnexpectedEOF {
+			errs[i] = nil
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range in {
+		switch {
+		case in[i] == nil:
+		case r.o.concReads:
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				read(i)
+			}(i)
+		default:
+			read(i)
+		}
+	}
+	wg.Wait()
+
+	// Use the size returned by most streams.
+	count := make(map[int]int)
+	size := 0
+	for i := range in {
+		if in[i] == nil || errs[i] != nil {
+			continue
+		}
+		n := sizes[i]
+		count[n]++
+		if count[n] > count[size] || (count[n] == count[size] && n > size) {
+			size = n
+		}
+	}
+
+	present := 0
+	var failed error
+	for i := range in {
+		if in[i] == nil {
+			dst[i] = dst[i][:0]
+			continue
+		}
+		err := errs[i]
+		if err == nil && sizes[i] == size {
+			dst[i] = dst[i][:size]
+			present++
+			continue
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		failed = StreamReadError{Err: err, Stream: i}
+		r.dropStream(in, i, offset+int64(sizes[i]), err)
+		dst[i] = dst[i][:0]
+	}
+	if failed != nil && present < r.r.dataShards {
+		return failed
+	}
+	if size == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+// dropStream removes stream i from in and reports it.
+func (r *rsStream) dropStream(in []io.Reader, i int, offset int64, err error) {
+	in[i] = nil
+	if r.o.streamDropped != nil {
+		r.o.streamDropped(StreamDropped{Stream: i, Offset: offset, Err: err})
+	}
+}
+
 type readResult struct {
 	n    int
 	size int
@@ -415,38 +998,165 @@ func cWriteShards(out []io.Writer, in [][]byte) error {
 	return nil
 }
 
-// Verify returns true if the parity shards contain correct data.
-//
-// The number of shards must match the number total data+parity shards
-// given to NewStream().
-//
-// Each reader must supply the same number of bytes.
-// If a shard stream returns an error, a StreamReadError type error
-// will be returned.
-func (r *rsStream) Verify(shards []io.Reader) (bool, error) {
-	if len(shards) != r.r.totalShards {
-		return false, ErrTooFewShards
+// encodePipelined encodes with reading, encoding and writing running
+// concurrently, with up to r.o.pipeline blocks in flight.
+func (r *rsStream) encodePipelined(data []io.Reader, parity []io.Writer, offset int64) error {
+	depth := r.o.pipeline
+	free := make(chan [][]byte, depth)
+	buffers := make([][][]byte, depth)
+	for i := range buffers {
+		buffers[i] = r.createSlice()
+		free <- buffers[i]
 	}
+	defer func() {
+		for _, b := range buffers {
+			r.blockPool.Put(b)
+		}
+	}()
 
-	read := 0
-	all := r.createSlice()
-	defer r.blockPool.Put(all)
-	for {
-		err := r.readShards(all, shards)
-		if err == io.EOF {
-			if read == 0 {
-				return false, ErrShardNoData
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		err     error
+	)
+	done := make(chan struct{})
+	fail := func(e error) {
+		errOnce.Do(func() {
+			err = e
+			close(done)
+		})
+	}
+	toEncode := make(chan [][]byte, depth)
+	toWrite := make(chan [][]byte, depth)
+	stats, data, parity := r.newStats(data, parity)
+
+	// Read blocks.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(toEncode)
+		read := 0
+		for {
+			var all [][]byte
+			select {
+			case all = <-free:
+			case <-done:
+				return
+			}
+			in := all[:r.r.dataShards]
+			for i := range in {
+				in[i] = in[i][:r.o.streamBS]
+			}
+			switch err := r.readShards(in, data); err {
+			case nil:
+			case io.EOF:
+				if read == 0 && offset == 0 {
+					fail(ErrShardNoData)
+				}
+				return
+			default:
+				fail(err)
+				return
+			}
+			read += shardSize(in)
+			select {
+			case toEncode <- all:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Write parity.
+	cp := r.newCheckpointer(offset)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for all := range toWrite {
+			if err := r.writeShards(parity, all[r.r.dataShards:]); err != nil {
+				fail(err)
+				return
+			}
+			cp.written(shardSize(all[:r.r.dataShards]))
+			stats.block()
+			free <- all
+		}
+	}()
+
+	// Encode blocks.
+	var block int64
+	pos := offset
+	for all := range toEncode {
+		size := shardSize(all[:r.r.dataShards])
+		trimShards(all[r.r.dataShards:], size)
+		if err := r.r.Encode(all); err != nil {
+			fail(err)
+			break
+		}
+		if err := r.blockHook(block, pos, all); err != nil {
+			fail(err)
+			break
+		}
+		block++
+		pos += int64(size)
+		toWrite <- all
+	}
+	close(toWrite)
+	wg.Wait()
+	if err == nil {
+		cp.finish()
+	}
+	return err
+}
+
+// Verify returns true if the parity shards contain correct data.
+//
+// The number of shards must match the number total data+parity shards
+// given to NewStream().
+//
+// Each reader must supply the same number of bytes.
+// If a shard stream returns an error, a StreamReadError type error
+// will be returned.
+func (r *rsStream) Verify(shards []io.Reader) (bool, error) {
+	if len(shards) != r.r.totalShards {
+		return false, ErrTooFewShards
+	}
+
+	if r.o.dropStreams {
+		shards = slices.Clone(shards)
+	}
+	read := 0
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	stats, shards, _ := r.newStats(shards, nil)
+	for block := int64(0); ; block++ {
+		err := r.readBlock(all, shards, int64(read))
+		if err == io.EOF {
+			if read == 0 {
+				return false, ErrShardNoData
 			}
 			return true, nil
 		}
 		if err != nil {
 			return false, err
 		}
-		read += shardSize(all)
+		size := shardSize(all)
+		if err := r.blockHook(block, int64(read), all); err != nil {
+			return false, err
+		}
+		read += size
+		if r.o.dropStreams && slices.Contains(shards, nil) {
+			// Verify the remaining shards.
+			all = trimShards(all, size)
+			if err := r.r.Reconstruct(all); err != nil {
+				return false, err
+			}
+		}
 		ok, err := r.r.Verify(all)
 		if !ok || err != nil {
 			return ok, err
 		}
+		stats.block()
 	}
 }
 
@@ -456,6 +1166,102 @@ func (r *rsStream) Verify(shards []io.Reader) (bool, error) {
 // or would like to have it reconstructed.
 var ErrReconstructMismatch = errors.New("valid shards and fill shards are mutually exclusive")
 
+// StreamMismatch describes a block where the parity shards do not match the data.
+// See VerifyDetailed.
+type StreamMismatch struct {
+	Offset int64 // The offset of the block within each shard
+	Length int   // The length of the block
+	Parity []int // The indexes of the parity shards that do not match, counted from the first data shard
+	// Suspect is the index of the shard that is likely corrupt,
+	// or -1 if it could not be identified.
+	Suspect int
+}
+
+// VerifyDetailed returns the blocks where the parity shards do not match the data.
+// If no mismatches are found, nil is returned.
+//
+// The number of shards must match the number total data+parity shards
+// given to NewStream().
+// Each reader must supply the same number of bytes.
+//
+// If there are at least 2 parity shards, the shard that is likely corrupt is
+// identified for each block, assuming only one shard of the block is corrupt.
+// Repair can then be limited to the reported regions of that shard.
+//
+// If a shard stream returns an error, a StreamReadError type error
+// will be returned.
+func (r *rsStream) VerifyDetailed(shards []io.Reader) ([]StreamMismatch, error) {
+	if len(shards) != r.r.totalShards {
+		return nil, ErrTooFewShards
+	}
+
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	parity := AllocAligned(r.r.parityShards, r.o.streamBS)
+	var mismatches []StreamMismatch
+	var read int64
+	for {
+		err := r.readShards(all, shards)
+		if err == io.EOF {
+			if read == 0 {
+				return nil, ErrShardNoData
+			}
+			return mismatches, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkShards(all, false); err != nil {
+			return nil, err
+		}
+		size := shardSize(all)
+		parity = trimShards(parity, size)
+		r.r.codeSomeShards(r.r.parity, all[:r.r.dataShards], parity, size, true)
+		var failed []int
+		for i := range parity {
+			if !bytes.Equal(parity[i], all[r.r.dataShards+i]) {
+				failed = append(failed, r.r.dataShards+i)
+			}
+		}
+		if len(failed) > 0 {
+			mismatches = append(mismatches, StreamMismatch{
+				Offset:  read,
+				Length:  size,
+				Parity:  failed,
+				Suspect: r.findSuspect(all),
+			})
+		}
+		read += int64(size)
+	}
+}
+
+// findSuspect returns the only shard that makes the block verify if it is reconstructed,
+// or -1 if there isn't exactly one.
+func (r *rsStream) findSuspect(all [][]byte) int {
+	// With a single parity shard, any shard can be reconstructed to match.
+	if r.r.parityShards < 2 {
+		return -1
+	}
+	size := len(all[0])
+	scratch := make([]byte, size)
+	test := make([][]byte, len(all))
+	suspect := -1
+	for i := range all {
+		copy(test, all)
+		test[i] = scratch[:0]
+		if err := r.r.Reconstruct(test); err != nil {
+			return -1
+		}
+		if ok, _ := r.r.Verify(test); ok {
+			if suspect >= 0 {
+				return -1
+			}
+			suspect = i
+		}
+	}
+	return suspect
+}
+
 // Reconstruct will recreate the missing shards if possible.
 //
 // Given a list of valid shards (to read) and invalid shards (to write)
@@ -490,9 +1296,13 @@ func (r *rsStream) Reconstruct(valid []io.Reader, fill []io.Writer) error {
 		}
 	}
 
+	if r.o.dropStreams {
+		valid = slices.Clone(valid)
+	}
+	stats, valid, fill := r.newStats(valid, fill)
 	read := 0
-	for {
-		err := r.readShards(all, valid)
+	for block := int64(0); ; block++ {
+		err := r.readBlock(all, valid, int64(read))
 		if err == io.EOF {
 			if read == 0 {
 				return ErrShardNoData
@@ -502,8 +1312,8 @@ func (r *rsStream) Reconstruct(valid []io.Reader, fill []io.Writer) error {
 		if err != nil {
 			return err
 		}
-		read += shardSize(all)
-		all = trimShards(all, shardSize(all))
+		size := shardSize(all)
+		all = trimShards(all, size)
 
 		if reconDataOnly {
 			err = r.r.ReconstructData(all) // just reconstruct missing data shards
@@ -513,11 +1323,295 @@ func (r *rsStream) Reconstruct(valid []io.Reader, fill []io.Writer) error {
 		if err != nil {
 			return err
 		}
+		if err := r.blockHook(block, int64(read), all); err != nil {
+			return err
+		}
+		read += size
 		err = r.writeShards(fill, all)
 		if err != nil {
 			return err
 		}
+		stats.block()
+	}
+}
+
+// ReconstructData will recreate the missing data shards if possible.
+//
+// This functions as Reconstruct, but only data shards are recreated,
+// and fill writers for parity shards are ignored.
+// Only DataShards of the valid shards are read, preferring data shards.
+//
+// If there are too few shards to reconstruct the missing
+// ones, ErrTooFewShards will be returned.
+func (r *rsStream) ReconstructData(valid []io.Reader, fill []io.Writer) error {
+	if len(fill) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	fill = slices.Clone(fill)
+	clear(fill[r.r.dataShards:])
+	return r.ReconstructSome(valid, fill)
+}
+
+// ReconstructSome will recreate the shards with a non-nil fill writer if possible.
+//
+// This functions as Reconstruct, but only DataShards of the valid shards are read,
+// preferring data shards, so parity shards that aren't needed are not read.
+//
+// If there are too few shards to reconstruct the missing
+// ones, ErrTooFewShards will be returned.
+func (r *rsStream) ReconstructSome(valid []io.Reader, fill []io.Writer) error {
+	if len(valid) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	if len(fill) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+
+	// Select the shards to read.
+	// If failures are tolerated, all shards are read.
+	use := make([]io.Reader, len(valid))
+	required := make([]bool, len(valid))
+	present := 0
+	for i := range valid {
+		if valid[i] != nil && fill[i] != nil {
+			return ErrReconstructMismatch
+		}
+		required[i] = fill[i] != nil
+		if valid[i] != nil && (present < r.r.dataShards || r.o.dropStreams) {
+			use[i] = valid[i]
+			present++
+		}
+	}
+	if present < r.r.dataShards {
+		return ErrTooFewShards
+	}
+
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	read := 0
+	for {
+		err := r.readBlock(all, use, int64(read))
+		if err == io.EOF {
+			if read == 0 {
+				return ErrShardNoData
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		size := shardSize(all)
+		read += size
+		all = trimShards(all, size)
+		if err := r.r.ReconstructSome(all, required); err != nil {
+			return err
+		}
+		if err := r.writeShards(fill, all); err != nil {
+			return err
+		}
+	}
+}
+
+// EncodeAt encodes parity shards for size bytes of data shards using positional I/O.
+//
+// The number of data and parity shards must match the number given to NewStream().
+// Blocks are read, encoded and written in parallel. Up to the number of blocks
+// set by WithStreamPipelineDepth are processed concurrently, or GOMAXPROCS if not set.
+//
+// If a data shard contains less than size bytes, ErrShortData will be returned.
+// If a data stream returns an error, a StreamReadError type error
+// will be returned. If a parity writer returns an error, a
+// StreamWriteError will be returned.
+func (r *rsStream) EncodeAt(data []io.ReaderAt, parity []io.WriterAt, size int64) error {
+	if len(data) != r.r.dataShards {
+		return ErrTooFewShards
+	}
+	if len(parity) != r.r.parityShards {
+		return ErrTooFewShards
+	}
+	if size <= 0 {
+		return ErrShardNoData
+	}
+	for i := range data {
+		if data[i] == nil {
+			return StreamReadError{Err: ErrShardNoData, Stream: i}
+		}
+	}
+	return r.parallelBlocks(size, func(all [][]byte, off int64) error {
+		if err := readShardsAt(all[:r.r.dataShards], data, off); err != nil {
+			return err
+		}
+		if err := r.r.Encode(all); err != nil {
+			return err
+		}
+		return writeShardsAt(parity, all[r.r.dataShards:], off)
+	})
+}
+
+// errVerifyFailed stops VerifyAt when a block fails verification.
+var errVerifyFailed = errors.New("verification failed")
+
+// VerifyAt returns true if the parity shards contain correct data,
+// reading size bytes of all shards using positional I/O.
+//
+// The number of shards must match the number total data+parity shards
+// given to NewStream().
+// Blocks are read and verified in parallel, see EncodeAt.
+//
+// If a shard contains less than size bytes, ErrShortData will be returned.
+// If a shard stream returns an error, a StreamReadError type error
+// will be returned.
+func (r *rsStream) VerifyAt(shards []io.ReaderAt, size int64) (bool, error) {
+	if len(shards) != r.r.totalShards {
+		return false, ErrTooFewShards
+	}
+	if size <= 0 {
+		return false, ErrShardNoData
+	}
+	err := r.parallelBlocks(size, func(all [][]byte, off int64) error {
+		if err := readShardsAt(all, shards, off); err != nil {
+			return err
+		}
+		ok, err := r.r.Verify(all)
+		if err == nil && !ok {
+			err = errVerifyFailed
+		}
+		return err
+	})
+	if err == errVerifyFailed {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ReconstructAt will recreate size bytes of the missing shards if possible,
+// using positional I/O.
+//
+// You indicate that a shard is missing by setting it to nil in the 'valid'
+// slice and at the same time setting a non-nil writer in "fill".
+// An index cannot contain both non-nil 'valid' and 'fill' entry.
+// If both are provided 'ErrReconstructMismatch' is returned.
+// Blocks are read, reconstructed and written in parallel, see EncodeAt.
+//
+// If there are too few shards to reconstruct the missing
+// ones, ErrTooFewShards will be returned.
+func (r *rsStream) ReconstructAt(valid []io.ReaderAt, fill []io.WriterAt, size int64) error {
+	if len(valid) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	if len(fill) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	if size <= 0 {
+		return ErrShardNoData
+	}
+	reconDataOnly := true
+	for i := range valid {
+		if valid[i] != nil && fill[i] != nil {
+			return ErrReconstructMismatch
+		}
+		if i >= r.r.dataShards && fill[i] != nil {
+			reconDataOnly = false
+		}
+	}
+	return r.parallelBlocks(size, func(all [][]byte, off int64) error {
+		if err := readShardsAt(all, valid, off); err != nil {
+			return err
+		}
+		var err error
+		if reconDataOnly {
+			err = r.r.ReconstructData(all)
+		} else {
+			err = r.r.Reconstruct(all)
+		}
+		if err != nil {
+			return err
+		}
+		return writeShardsAt(fill, all, off)
+	})
+}
+
+// parallelBlocks calls fn for each block of size bytes,
+// with up to streamConc blocks in parallel.
+// all will contain TotalShards slices with the size of the block.
+// The first error returned by fn stops processing and is returned.
+func (r *rsStream) parallelBlocks(size int64, fn func(all [][]byte, off int64) error) error {
+	bs := int64(r.o.streamBS)
+	blocks := (size + bs - 1) / bs
+	workers := int(min(int64(r.o.streamConc), blocks))
+
+	var (
+		wg      sync.WaitGroup
+		next    atomic.Int64
+		errOnce sync.Once
+		failed  atomic.Bool
+		err     error
+	)
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			all := r.createSlice()
+			defer r.blockPool.Put(all)
+			for !failed.Load() {
+				block := next.Add(1) - 1
+				if block >= blocks {
+					return
+				}
+				off := block * bs
+				n := min(bs, size-off)
+				for i := range all {
+					all[i] = all[i][:n]
+				}
+				if e := fn(all, off); e != nil {
+					errOnce.Do(func() {
+						err = e
+						failed.Store(true)
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return err
+}
+
+// readShardsAt fills dst from the non-nil readers in 'in' at offset off.
+// dst is set to zero length for nil readers.
+func readShardsAt(dst [][]byte, in []io.ReaderAt, off int64) error {
+	for i := range in {
+		if in[i] == nil {
+			dst[i] = dst[i][:0]
+			continue
+		}
+		n, err := in[i].ReadAt(dst[i], off)
+		if n == len(dst[i]) {
+			continue
+		}
+		if err == io.EOF {
+			return ErrShortData
+		}
+		return StreamReadError{Err: err, Stream: i}
+	}
+	return nil
+}
+
+// writeShardsAt writes the shards to the non-nil writers in 'out' at offset off.
+func writeShardsAt(out []io.WriterAt, in [][]byte, off int64) error {
+	for i := range out {
+		if out[i] == nil {
+			continue
+		}
+		n, err := out[i].WriteAt(in[i], off)
+		if err != nil {
+			return StreamWriteError{Err: err, Stream: i}
+		}
+		if n != len(in[i]) {
+			return StreamWriteError{Err: io.ErrShortWrite, Stream: i}
+		}
 	}
+	return nil
 }
 
 // Join the shards and write the data segment to dst.
@@ -533,6 +1627,14 @@ func (r *rsStream) Join(dst io.Writer, shards []io.Reader, outSize int64) error
 		return ErrTooFewShards
 	}
 
+	if r.o.dropStreams {
+		all := make([]io.Reader, r.r.totalShards)
+		copy(all, shards)
+		if canReconstructJoin(dst, all) {
+			return r.reconstructJoin(dst, all, outSize)
+		}
+	}
+
 	// Trim off parity shards if any
 	shards = shards[:r.r.dataShards]
 	for i := range shards {
@@ -540,6 +1642,10 @@ func (r *rsStream) Join(dst io.Writer, shards []io.Reader, outSize int64) error
 			return StreamReadError{Err: ErrShardNoData, Stream: i}
 		}
 	}
+	if r.o.progress != nil || r.o.blockHook != nil {
+		return r.joinBlocks(dst, shards, outSize)
+	}
+
 	// Join all shards
 	src := io.MultiReader(shards...)
 
@@ -557,6 +1663,314 @@ func (r *rsStream) Join(dst io.Writer, shards []io.Reader, outSize int64) error
 	return nil
 }
 
+// joinBlocks joins the shards block by block,
+// calling the block hook and reporting progress.
+func (r *rsStream) joinBlocks(dst io.Writer, shards []io.Reader, outSize int64) error {
+	stats, shards, out := r.newStats(shards, []io.Writer{dst})
+	dst = out[0]
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	blocks := make([][]byte, r.r.totalShards)
+	var block, written int64
+	for i := range shards {
+		for off := int64(0); written < outSize; block++ {
+			buf := all[i][:min(int64(r.o.streamBS), outSize-written)]
+			n, err := io.ReadFull(shards[i], buf)
+			if n > 0 {
+				blocks[i] = buf[:n]
+				if err := r.blockHook(block, off, blocks); err != nil {
+					return err
+				}
+				blocks[i] = nil
+				if _, err := dst.Write(buf[:n]); err != nil {
+					return err
+				}
+				written += int64(n)
+				off += int64(n)
+				stats.block()
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if written != outSize {
+		return ErrShortData
+	}
+	return nil
+}
+
+// ReconstructJoin will join the data shards and write outSize bytes to dst,
+// rebuilding missing data shards in memory as needed.
+//
+// shards must contain TotalShards entries, and the data shards must
+// be created by Split with outSize as size.
+// Missing shards are indicated by a nil reader.
+// At most DataShards shards are read, preferring data shards.
+//
+// If no data shards are missing, this is equivalent to Join.
+// Otherwise, if dst implements io.WriterAt, all shards are read in a single
+// pass and each block is written at its position in the output.
+// If dst does not implement io.WriterAt, the readers must implement io.ReaderAt,
+// and blocks are read in output order.
+// ErrNotSupported is returned if neither is the case.
+//
+// If there are too few shards to reconstruct the missing
+// ones, ErrTooFewShards will be returned.
+// If the shards contain less than outSize bytes, ErrShortData will be returned.
+func (r *rsStream) ReconstructJoin(dst io.Writer, shards []io.Reader, outSize int64) error {
+	if len(shards) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	if outSize <= 0 {
+		return ErrShortData
+	}
+
+	// Select the shards to read.
+	// If failures are tolerated, all shards are read.
+	use := make([]io.Reader, len(shards))
+	dataMissing := false
+	present := 0
+	for i, s := range shards {
+		switch {
+		case s == nil:
+			dataMissing = dataMissing || i < r.r.dataShards
+		case present < r.r.dataShards || r.o.dropStreams:
+			use[i] = s
+			present++
+		}
+	}
+	if !dataMissing && (!r.o.dropStreams || !canReconstructJoin(dst, use)) {
+		return r.Join(dst, shards, outSize)
+	}
+	if present < r.r.dataShards {
+		return ErrTooFewShards
+	}
+	return r.reconstructJoin(dst, use, outSize)
+}
+
+// canReconstructJoin returns whether reconstructJoin supports dst and shards.
+func canReconstructJoin(dst io.Writer, shards []io.Reader) bool {
+	if _, ok := dst.(io.WriterAt); ok {
+		return true
+	}
+	for _, s := range shards {
+		if _, ok := s.(io.ReaderAt); s != nil && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reconstructJoin writes outSize bytes of the data shards to dst,
+// reconstructing missing data.
+// All non-nil shards may be read.
+func (r *rsStream) reconstructJoin(dst io.Writer, shards []io.Reader, outSize int64) error {
+	perShard := (outSize + int64(r.r.dataShards) - 1) / int64(r.r.dataShards)
+	if w, ok := dst.(io.WriterAt); ok {
+		return r.reconstructJoinWriterAt(w, shards, outSize, perShard)
+	}
+	readers := make([]io.ReaderAt, len(shards))
+	for i, s := range shards {
+		if s == nil {
+			continue
+		}
+		ra, ok := s.(io.ReaderAt)
+		if !ok {
+			return ErrNotSupported
+		}
+		readers[i] = ra
+	}
+	return r.reconstructJoinReaderAt(dst, readers, outSize, perShard)
+}
+
+// reconstructJoinWriterAt reads all shards in a single pass,
+// and writes the data blocks at their position in dst.
+func (r *rsStream) reconstructJoinWriterAt(dst io.WriterAt, shards []io.Reader, outSize, perShard int64) error {
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	var read int64
+	for read < perShard {
+		// Don't read beyond the data.
+		for i := range all {
+			all[i] = all[i][:min(int64(r.o.streamBS), perShard-read)]
+		}
+		err := r.readBlock(all, shards, read)
+		if err == io.EOF {
+			return ErrShortData
+		}
+		if err != nil {
+			return err
+		}
+		size := shardSize(all)
+		all = trimShards(all, size)
+		if err := r.r.ReconstructData(all); err != nil {
+			return err
+		}
+		for i, block := range all[:r.r.dataShards] {
+			off := int64(i)*perShard + read
+			if off >= outSize {
+				break
+			}
+			block = block[:min(int64(len(block)), outSize-off)]
+			if _, err := dst.WriteAt(block, off); err != nil {
+				return err
+			}
+		}
+		read += int64(size)
+	}
+	return nil
+}
+
+// reconstructJoinReaderAt reads blocks in output order.
+// Present data shards are copied directly.
+// For missing data shards, the block is read from DataShards other shards
+// and the missing shard is reconstructed.
+func (r *rsStream) reconstructJoinReaderAt(dst io.Writer, shards []io.ReaderAt, outSize, perShard int64) error {
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	required := make([]bool, r.r.dataShards)
+	for i := range r.r.dataShards {
+		todo := min(perShard, outSize-int64(i)*perShard)
+		for off := int64(0); off < todo; off += int64(r.o.streamBS) {
+			n := min(int64(r.o.streamBS), perShard-off)
+			block := all[i][:n]
+			if shards[i] != nil {
+				err := r.readShardAt(block, shards, i, off)
+				if err != nil && !r.o.dropStreams {
+					return err
+				}
+			}
+			if shards[i] == nil {
+				if err := r.reconstructAt(all, shards, i, off, n, required); err != nil {
+					return err
+				}
+				block = all[i]
+			}
+			block = block[:min(n, todo-off)]
+			if _, err := dst.Write(block); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reconstructAt reconstructs n bytes at offset off of data shard idx into all[idx],
+// reading from DataShards of the other shards.
+func (r *rsStream) reconstructAt(all [][]byte, shards []io.ReaderAt, idx int, off, n int64, required []bool) error {
+	got := 0
+	var failed error
+	for j := range all {
+		all[j] = all[j][:0]
+		if shards[j] == nil || got == r.r.dataShards {
+			continue
+		}
+		all[j] = all[j][:n]
+		if err := r.readShardAt(all[j], shards, j, off); err != nil {
+			if !r.o.dropStreams {
+				return err
+			}
+			all[j] = all[j][:0]
+			failed = err
+			continue
+		}
+		got++
+	}
+	if got < r.r.dataShards && failed != nil {
+		return failed
+	}
+	required[idx] = true
+	err := r.r.ReconstructSome(all, required)
+	required[idx] = false
+	return err
+}
+
+// readShardAt fills dst from shards[idx] at offset off.
+// If failure tolerance is enabled, a failing shard is dropped.
+func (r *rsStream) readShardAt(dst []byte, shards []io.ReaderAt, idx int, off int64) error {
+	n, err := shards[idx].ReadAt(dst, off)
+	if n == len(dst) {
+		return nil
+	}
+	if r.o.dropStreams {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		shards[idx] = nil
+		if r.o.streamDropped != nil {
+			r.o.streamDropped(StreamDropped{Stream: idx, Offset: off + int64(n), Err: err})
+		}
+		return StreamReadError{Err: err, Stream: idx}
+	}
+	if err == io.EOF {
+		return ErrShortData
+	}
+	return StreamReadError{Err: err, Stream: idx}
+}
+
+// SplitEncode splits an input stream into data shards and
+// encodes the parity shards, reading the input only once.
+//
+// The data shards are identical to the output of Split,
+// and the parity shards are identical to the output of Encode.
+// shards must contain TotalShards writers, data shards followed by parity shards.
+//
+// You must supply the total size of your input.
+// 'ErrShortData' will be returned if it is unable to retrieve the
+// number of bytes indicated.
+//
+// Each block of all data shards is read from its position in the input,
+// parity is encoded in the same buffers, and all shards are written
+// block by block, so only a single block of each shard is kept in memory.
+// Use SplitEncodeStream for inputs that can only be read in order.
+//
+// If a shard writer returns an error, a StreamWriteError will be returned.
+func (r *rsStream) SplitEncode(data io.ReaderAt, size int64, shards []io.Writer) error {
+	if size <= 0 {
+		return ErrShortData
+	}
+	if len(shards) != r.r.totalShards {
+		return ErrTooFewShards
+	}
+	for i := range shards {
+		if shards[i] == nil {
+			return StreamWriteError{Err: ErrShardNoData, Stream: i}
+		}
+	}
+	perShard := (size + int64(r.r.dataShards) - 1) / int64(r.r.dataShards)
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	for off := int64(0); off < perShard; off += int64(r.o.streamBS) {
+		all = trimShards(all, int(min(int64(r.o.streamBS), perShard-off)))
+		for i, block := range all[:r.r.dataShards] {
+			// Read what remains of the input, and pad with zeros.
+			pos := int64(i)*perShard + off
+			n := min(int64(len(block)), max(size-pos, 0))
+			if n > 0 {
+				got, err := data.ReadAt(block[:n], pos)
+				if int64(got) != n {
+					if err == io.EOF {
+						return ErrShortData
+					}
+					return err
+				}
+			}
+			clear(block[n:])
+		}
+		if err := r.r.Encode(all); err != nil {
+			return err
+		}
+		if err := r.writeShards(shards, all); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Split a an input stream into the number of shards given to the encoder.
 //
 // The data will be split into equally sized shards.
@@ -586,6 +2000,9 @@ func (r *rsStream) Split(data io.Reader, dst []io.Writer, size int64) error {
 	// Pad data to r.Shards*perShard.
 	paddingSize := (int64(r.r.totalShards) * perShard) - size
 	data = io.MultiReader(data, io.LimitReader(zeroPaddingReader{}, paddingSize))
+	if r.o.progress != nil || r.o.blockHook != nil {
+		return r.splitBlocks(data, dst, perShard)
+	}
 
 	// Split into equal-length shards and copy.
 	for i := range dst {
@@ -601,6 +2018,251 @@ func (r *rsStream) Split(data io.Reader, dst []io.Writer, size int64) error {
 	return nil
 }
 
+// streamTrailerSize is the size of the trailer
+// added to the final stripe by SplitStream.
+const streamTrailerSize = 8
+
+// splitBlocks splits data into perShard bytes per shard block by block,
+// calling the block hook and reporting progress.
+func (r *rsStream) splitBlocks(data io.Reader, dst []io.Writer, perShard int64) error {
+	stats, in, dst := r.newStats([]io.Reader{data}, dst)
+	data = in[0]
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	blocks := make([][]byte, r.r.totalShards)
+	var block int64
+	for i := range dst {
+		for off := int64(0); off < perShard; off += int64(r.o.streamBS) {
+			buf := all[i][:min(int64(r.o.streamBS), perShard-off)]
+			if _, err := io.ReadFull(data, buf); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return ErrShortData
+				}
+				return err
+			}
+			blocks[i] = buf
+			if err := r.blockHook(block, off, blocks); err != nil {
+				return err
+			}
+			blocks[i] = nil
+			if _, err := dst[i].Write(buf); err != nil {
+				return err
+			}
+			block++
+			stats.block()
+		}
+	}
+	return nil
+}
+
+// SplitStream splits an input stream of unknown length into data shards,
+// reading until io.EOF.
+//
+// The input is split into stripes of block size bytes per data shard.
+// Each data shard receives a block of each stripe in turn.
+// The final stripe is smaller and ends with an 8 byte trailer
+// containing the total input size, so all shards will have the same size.
+// The shards must be joined by JoinStream with the same block size.
+// They can be encoded, verified and reconstructed as any other shards.
+//
+// The total size of the input is returned.
+func (r *rsStream) SplitStream(data io.Reader, dst []io.Writer) (int64, error) {
+	if len(dst) != r.r.dataShards {
+		return 0, ErrInvShardNum
+	}
+	return r.splitStream(data, dst)
+}
+
+// SplitEncodeStream splits an input stream of unknown length
+// and encodes the parity shards, reading until io.EOF.
+//
+// The data shards are identical to the output of SplitStream,
+// and the parity shards are identical to the output of Encode.
+// shards must contain TotalShards writers, data shards followed by parity shards.
+//
+// The total size of the input is returned.
+func (r *rsStream) SplitEncodeStream(data io.Reader, shards []io.Writer) (int64, error) {
+	if len(shards) != r.r.totalShards {
+		return 0, ErrTooFewShards
+	}
+	return r.splitStream(data, shards)
+}
+
+// splitStream splits data into stripes and writes them to shards.
+// If shards contains parity shards, they are encoded as well.
+func (r *rsStream) splitStream(data io.Reader, shards []io.Writer) (int64, error) {
+	for i := range shards {
+		if shards[i] == nil {
+			return 0, StreamWriteError{Err: ErrShardNoData, Stream: i}
+		}
+	}
+	dataShards := r.r.dataShards
+	bs := r.o.streamBS
+	buf := make([]byte, dataShards*bs)
+	all := r.createSlice()
+	defer r.blockPool.Put(all)
+	stripe := make([][]byte, len(shards))
+
+	// write a stripe of buf with perShard bytes per shard.
+	write := func(perShard int) error {
+		for i := range stripe {
+			if i < dataShards {
+				stripe[i] = buf[i*perShard : (i+1)*perShard]
+			} else {
+				stripe[i] = all[i][:perShard]
+			}
+		}
+		if len(stripe) > dataShards {
+			if err := r.r.Encode(stripe); err != nil {
+				return err
+			}
+		}
+		return r.writeShards(shards, stripe)
+	}
+
+	var total int64
+	for {
+		n, err := io.ReadFull(data, buf)
+		total += int64(n)
+		switch err {
+		case nil:
+			if err := write(bs); err != nil {
+				return total, err
+			}
+			continue
+		case io.EOF, io.ErrUnexpectedEOF:
+		default:
+			return total, err
+		}
+
+		// Final stripe. If the trailer doesn't fit,
+		// write the remaining data as a padded stripe first.
+		if n+streamTrailerSize > len(buf) {
+			clear(buf[n:])
+			if err := write(bs); err != nil {
+				return total, err
+			}
+			n = 0
+		}
+		perShard := (n + streamTrailerSize + dataShards - 1) / dataShards
+		end := perShard * dataShards
+		clear(buf[n:end])
+		binary.LittleEndian.PutUint64(buf[end-streamTrailerSize:end], uint64(total))
+		return total, write(perShard)
+	}
+}
+
+// JoinStream joins data shards created by SplitStream or SplitEncodeStream
+// and writes the original data to dst.
+//
+// Only the data shards are considered.
+// The size of the output is read from the trailer, and padding is removed.
+// The number of bytes written to dst is returned.
+//
+// If there are too few shards given, ErrTooFewShards will be returned.
+// If the trailer does not match the data, ErrShortData will be returned.
+func (r *rsStream) JoinStream(dst io.Writer, shards []io.Reader) (int64, error) {
+	if len(shards) < r.r.dataShards {
+		return 0, ErrTooFewShards
+	}
+	shards = shards[:r.r.dataShards]
+	for i := range shards {
+		if shards[i] == nil {
+			return 0, StreamReadError{Err: ErrShardNoData, Stream: i}
+		}
+	}
+
+	// Each stripe is held back until the next is read,
+	// since the final stripe can be followed by a stripe with only the trailer.
+	cur, prev := r.createSlice(), r.createSlice()
+	defer r.blockPool.Put(cur)
+	defer r.blockPool.Put(prev)
+	// The slices are swapped, so keep the ones to return.
+	curData, prevData := cur[:r.r.dataShards], prev[:r.r.dataShards]
+	havePrev := false
+	var written int64
+	for {
+		for i := range curData {
+			curData[i] = curData[i][:r.o.streamBS]
+		}
+		err := r.readShards(curData, shards)
+		size := 0
+		switch err {
+		case nil:
+			size = shardSize(curData)
+		case io.EOF:
+		default:
+			return written, err
+		}
+		if size == r.o.streamBS {
+			if havePrev {
+				n, err := writeJoined(dst, prevData, int64(size*len(prevData)))
+				written += n
+				if err != nil {
+					return written, err
+				}
+			}
+			curData, prevData = prevData, curData
+			havePrev = true
+			continue
+		}
+
+		// Find the final stripe.
+		final := curData
+		if size == 0 {
+			if !havePrev {
+				return written, ErrShortData
+			}
+			final, havePrev = prevData, false
+		}
+		var trailer [streamTrailerSize]byte
+		left := streamTrailerSize
+		for i := len(final) - 1; i >= 0 && left > 0; i-- {
+			n := min(left, len(final[i]))
+			copy(trailer[left-n:left], final[i][len(final[i])-n:])
+			left -= n
+		}
+		remain := int64(binary.LittleEndian.Uint64(trailer[:])) - written
+		avail := int64(len(final[0])*len(final)) - streamTrailerSize
+		if havePrev {
+			avail += int64(len(prevData[0]) * len(prevData))
+		}
+		if remain < 0 || remain > avail {
+			return written, ErrShortData
+		}
+		if havePrev {
+			n, err := writeJoined(dst, prevData, remain)
+			written += n
+			remain -= n
+			if err != nil {
+				return written, err
+			}
+		}
+		n, err := writeJoined(dst, final, remain)
+		return written + n, err
+	}
+}
+
+// writeJoined writes up to n bytes of the shards to dst in order.
+func writeJoined(dst io.Writer, shards [][]byte, n int64) (int64, error) {
+	var written int64
+	for _, shard := range shards {
+		if written >= n {
+			break
+		}
+		shard = shard[:min(int64(len(shard)), n-written)]
+		m, err := dst.Write(shard)
+		written += int64(m)
+		if err != nil {
+			return written, err
+		}
+		if m != len(shard) {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
+
 type zeroPaddingReader struct{}
 
 var _ io.Reader = &zeroPaddingReader{}
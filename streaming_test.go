@@ -8,8 +8,11 @@ package reedsolomon
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -676,6 +679,945 @@ func TestStreamSplitJoin(t *testing.T) {
 	}
 }
 
+// writerAtBuffer is an in-memory io.WriterAt and io.WriteSeeker.
+// It is safe for concurrent use.
+type writerAtBuffer struct {
+	mu  sync.Mutex
+	b   []byte
+	pos int64
+}
+
+func (w *writerAtBuffer) Write(p []byte) (int, error) {
+	n, err := w.WriteAt(p, w.pos)
+	w.pos += int64(n)
+	return n, err
+}
+
+func (w *writerAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if end := int(off) + len(p); end > len(w.b) {
+		w.b = append(w.b, make([]byte, end-len(w.b))...)
+	}
+	return copy(w.b[off:], p), nil
+}
+
+func (w *writerAtBuffer) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekStart {
+		return 0, errors.ErrUnsupported
+	}
+	w.pos = offset
+	return offset, nil
+}
+
+func TestStreamReconstructJoin(t *testing.T) {
+	var data = make([]byte, 250003)
+	fillRandom(data)
+
+	// Use a small block size to test multiple blocks.
+	enc, err := NewStream(5, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := emptyBuffers(8)
+	err = enc.Split(bytes.NewBuffer(data), toWriters(split[:5]), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.Encode(toReaders(toBuffers(toBytes(split[:5]))), toWriters(split[5:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := toBytes(split)
+
+	getReaders := func(missing ...int) []io.Reader {
+		r := make([]io.Reader, len(shards))
+		for i, s := range shards {
+			r[i] = bytes.NewReader(s)
+		}
+		for _, m := range missing {
+			r[m] = nil
+		}
+		return r
+	}
+	for _, missing := range [][]int{{}, {0}, {4}, {1, 3}, {0, 2, 4}, {0, 6}, {5, 6, 7}} {
+		// Single pass with io.WriterAt.
+		w := &writerAtBuffer{}
+		err := enc.ReconstructJoin(w, getReaders(missing...), int64(len(data)))
+		if err != nil {
+			t.Fatal(missing, err)
+		}
+		if !bytes.Equal(w.b, data) {
+			t.Fatal(missing, "recovered data does not match original")
+		}
+
+		// Output order with io.ReaderAt.
+		buf := new(bytes.Buffer)
+		err = enc.ReconstructJoin(buf, getReaders(missing...), int64(len(data)))
+		if err != nil {
+			t.Fatal(missing, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatal(missing, "recovered data does not match original")
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err = enc.ReconstructJoin(buf, getReaders(0, 1, 2, 3), int64(len(data)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	err = enc.ReconstructJoin(buf, getReaders()[:5], int64(len(data)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	err = enc.ReconstructJoin(&writerAtBuffer{}, getReaders(0), int64(len(data)+5))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+	err = enc.ReconstructJoin(buf, getReaders(0), int64(len(data)+5))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+	err = enc.ReconstructJoin(buf, toReaders(toBuffers(shards[1:])), int64(len(data)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	readers := toReaders(toBuffers(shards))
+	readers[0] = nil
+	err = enc.ReconstructJoin(buf, readers, int64(len(data)))
+	if err != ErrNotSupported {
+		t.Errorf("expected %v, got %v", ErrNotSupported, err)
+	}
+}
+
+func TestStreamSplitEncode(t *testing.T) {
+	var data = make([]byte, 250003)
+	fillRandom(data)
+
+	// Use a small block size to test multiple blocks.
+	enc, err := NewStream(5, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := emptyBuffers(8)
+	err = enc.Split(bytes.NewBuffer(data), toWriters(want[:5]), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.Encode(toReaders(toBuffers(toBytes(want[:5]))), toWriters(want[5:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := emptyBuffers(8)
+	err = enc.SplitEncode(bytes.NewReader(data), int64(len(data)), toWriters(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		if !bytes.Equal(got[i].Bytes(), want[i].Bytes()) {
+			t.Fatalf("shard %d does not match", i)
+		}
+	}
+
+	err = enc.SplitEncode(bytes.NewReader(data), int64(len(data)+1), toWriters(emptyBuffers(8)))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+	err = enc.SplitEncode(bytes.NewReader(data), 0, toWriters(emptyBuffers(8)))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+	err = enc.SplitEncode(bytes.NewReader(data), int64(len(data)), toWriters(emptyBuffers(5)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	err = enc.SplitEncode(bytes.NewReader(data), int64(len(data)), nilWriters(8))
+	if se, ok := err.(StreamWriteError); !ok || se.Err != ErrShardNoData || se.Stream != 0 {
+		t.Errorf("expected %v, got %v", StreamWriteError{Err: ErrShardNoData, Stream: 0}, err)
+	}
+}
+
+func TestStreamSplitJoinStream(t *testing.T) {
+	const bs = 1000
+	enc, err := NewStream(5, 3, testOptions(WithStreamBlockSize(bs))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, 5*bs - 8, 5*bs - 7, 5 * bs, 5*bs + 1, 17*bs + 5} {
+		data := make([]byte, size)
+		fillRandom(data)
+
+		split := emptyBuffers(5)
+		n, err := enc.SplitStream(bytes.NewBuffer(data), toWriters(split))
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		if n != int64(size) {
+			t.Fatalf("size %d: returned size %d", size, n)
+		}
+		shards := emptyBuffers(8)
+		n, err = enc.SplitEncodeStream(bytes.NewBuffer(data), toWriters(shards))
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		if n != int64(size) {
+			t.Fatalf("size %d: returned size %d", size, n)
+		}
+		for i := range split {
+			if !bytes.Equal(split[i].Bytes(), shards[i].Bytes()) {
+				t.Fatalf("size %d: shard %d does not match", size, i)
+			}
+		}
+		ok, err := enc.Verify(toReaders(toBuffers(toBytes(shards))))
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		if !ok {
+			t.Fatalf("size %d: verification failed", size)
+		}
+
+		// Reconstruct a data shard and join.
+		all := toBytes(shards)
+		fill := emptyBuffers(8)
+		valid := toReaders(toBuffers(all))
+		valid[1] = nil
+		fillW := nilWriters(8)
+		fillW[1] = fill[1]
+		if err := enc.Reconstruct(valid, fillW); err != nil {
+			t.Fatal(size, err)
+		}
+		all[1] = fill[1].Bytes()
+		buf := new(bytes.Buffer)
+		n, err = enc.JoinStream(buf, toReaders(toBuffers(all)))
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		if n != int64(size) || !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("size %d: joined data does not match", size)
+		}
+	}
+
+	_, err = enc.SplitStream(bytes.NewBuffer(nil), toWriters(emptyBuffers(3)))
+	if err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	_, err = enc.SplitEncodeStream(bytes.NewBuffer(nil), toWriters(emptyBuffers(5)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	_, err = enc.JoinStream(io.Discard, toReaders(emptyBuffers(2)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	_, err = enc.JoinStream(io.Discard, toReaders(emptyBuffers(5)))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+}
+
+var errTestFail = errors.New("test failure")
+
+// failAfter returns errTestFail when reading beyond n bytes.
+type failAfter struct {
+	b   []byte
+	pos int64
+	n   int64
+}
+
+func (f *failAfter) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+func (f *failAfter) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(f.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.b[off:min(f.n, int64(len(f.b)))])
+	if n < len(p) {
+		if off+int64(n) >= int64(len(f.b)) {
+			return n, io.EOF
+		}
+		return n, errTestFail
+	}
+	return n, nil
+}
+
+func TestStreamFailureTolerance(t *testing.T) {
+	var data = make([]byte, 250003)
+	fillRandom(data)
+
+	var dropped []StreamDropped
+	enc, err := NewStream(5, 3, testOptions(WithStreamBlockSize(10000), WithStreamFailureTolerance(func(d StreamDropped) {
+		dropped = append(dropped, d)
+	}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := emptyBuffers(8)
+	err = enc.Split(bytes.NewBuffer(data), toWriters(split[:5]), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.Encode(toReaders(toBuffers(toBytes(split[:5]))), toWriters(split[5:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := toBytes(split)
+
+	// getReaders returns readers where shard i fails after fail[i] bytes.
+	getReaders := func(fail map[int]int64) []io.Reader {
+		r := make([]io.Reader, len(shards))
+		for i, s := range shards {
+			f := &failAfter{b: s, n: int64(len(s))}
+			if n, ok := fail[i]; ok {
+				f.n = n
+			}
+			r[i] = f
+		}
+		return r
+	}
+	checkDropped := func(want ...StreamDropped) {
+		t.Helper()
+		if len(dropped) != len(want) {
+			t.Fatalf("expected %v dropped, got %v", want, dropped)
+		}
+		for i := range want {
+			if dropped[i] != want[i] {
+				t.Errorf("expected %v dropped, got %v", want[i], dropped[i])
+			}
+		}
+		dropped = nil
+	}
+
+	ok, err := enc.Verify(getReaders(map[int]int64{2: 25000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+	checkDropped(StreamDropped{Stream: 2, Offset: 25000, Err: errTestFail})
+
+	// A shard that ends early.
+	readers := getReaders(nil)
+	readers[0] = bytes.NewReader(shards[0][:15000])
+	ok, err = enc.Verify(readers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+	checkDropped(StreamDropped{Stream: 0, Offset: 15000, Err: io.ErrUnexpectedEOF})
+
+	readers = getReaders(map[int]int64{6: 1000})
+	readers[1] = nil
+	fill := emptyBuffers(8)
+	writers := nilWriters(8)
+	writers[1] = fill[1]
+	if err := enc.Reconstruct(readers, writers); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fill[1].Bytes(), shards[1]) {
+		t.Fatal("reconstructed shard does not match")
+	}
+	checkDropped(StreamDropped{Stream: 6, Offset: 1000, Err: errTestFail})
+
+	// Join with io.WriterAt
+	w := &writerAtBuffer{}
+	if err := enc.Join(w, getReaders(map[int]int64{3: 12345}), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.b, data) {
+		t.Fatal("joined data does not match")
+	}
+	checkDropped(StreamDropped{Stream: 3, Offset: 12345, Err: errTestFail})
+
+	// Join with io.ReaderAt
+	buf := new(bytes.Buffer)
+	if err := enc.Join(buf, getReaders(map[int]int64{1: 12345, 7: 0}), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("joined data does not match")
+	}
+	checkDropped(StreamDropped{Stream: 1, Offset: 12345, Err: errTestFail})
+
+	// Too many failures.
+	_, err = enc.Verify(getReaders(map[int]int64{0: 0, 1: 0, 2: 0, 3: 0}))
+	if se, ok := err.(StreamReadError); !ok || se.Err != errTestFail {
+		t.Errorf("expected %T, got %v", StreamReadError{}, err)
+	}
+	dropped = nil
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errTestFail
+}
+
+func TestStreamPipeline(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	want := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(want)); err != nil {
+		t.Fatal(err)
+	}
+	for _, depth := range []int{2, 3, 8} {
+		for _, conc := range []bool{false, true} {
+			enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000), WithStreamPipelineDepth(depth), WithConcurrentStreams(conc))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := emptyBuffers(3)
+			if err := enc.Encode(toReaders(toBuffers(data)), toWriters(got)); err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i].Bytes(), want[i].Bytes()) {
+					t.Fatalf("depth %d: parity shard %d does not match", depth, i)
+				}
+			}
+
+			readers := toReaders(toBuffers(data))
+			readers[4] = &failAfter{b: data[4], n: 25000}
+			err = enc.Encode(readers, toWriters(emptyBuffers(3)))
+			if se, ok := err.(StreamReadError); !ok || se.Err != errTestFail || se.Stream != 4 {
+				t.Errorf("expected %v, got %v", StreamReadError{Err: errTestFail, Stream: 4}, err)
+			}
+			writers := toWriters(emptyBuffers(3))
+			writers[1] = failWriter{}
+			err = enc.Encode(toReaders(toBuffers(data)), writers)
+			if se, ok := err.(StreamWriteError); !ok || se.Err != errTestFail || se.Stream != 1 {
+				t.Errorf("expected %v, got %v", StreamWriteError{Err: errTestFail, Stream: 1}, err)
+			}
+			err = enc.Encode(toReaders(emptyBuffers(6)), toWriters(emptyBuffers(3)))
+			if err != ErrShardNoData {
+				t.Errorf("expected %v, got %v", ErrShardNoData, err)
+			}
+		}
+	}
+}
+
+func TestStreamEncodeAt(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	want := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(want)); err != nil {
+		t.Fatal(err)
+	}
+	readersAt := func(in [][]byte) []io.ReaderAt {
+		r := make([]io.ReaderAt, len(in))
+		for i := range in {
+			if in[i] != nil {
+				r[i] = bytes.NewReader(in[i])
+			}
+		}
+		return r
+	}
+
+	parity := make([]*writerAtBuffer, 3)
+	writers := make([]io.WriterAt, 3)
+	for i := range parity {
+		parity[i] = &writerAtBuffer{}
+		writers[i] = parity[i]
+	}
+	if err := enc.EncodeAt(readersAt(data), writers, size); err != nil {
+		t.Fatal(err)
+	}
+	for i := range parity {
+		if !bytes.Equal(parity[i].b, want[i].Bytes()) {
+			t.Fatalf("parity shard %d does not match", i)
+		}
+	}
+	shards := append(data, toBytes(want)...)
+
+	for _, conc := range []int{1, 8} {
+		concEnc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000), WithStreamConcurrency(conc))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range parity {
+			parity[i] = &writerAtBuffer{}
+			writers[i] = parity[i]
+		}
+		if err := concEnc.EncodeAt(readersAt(data), writers, size); err != nil {
+			t.Fatal(err)
+		}
+		for i := range parity {
+			if !bytes.Equal(parity[i].b, want[i].Bytes()) {
+				t.Fatalf("concurrency %d: parity shard %d does not match", conc, i)
+			}
+		}
+		if ok, err := concEnc.VerifyAt(readersAt(shards), size); err != nil || !ok {
+			t.Fatalf("concurrency %d: verification failed: %v", conc, err)
+		}
+	}
+
+	ok, err := enc.VerifyAt(readersAt(shards), size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("verification failed")
+	}
+	corrupt := make([][]byte, len(shards))
+	copy(corrupt, shards)
+	corrupt[7] = bytes.Clone(shards[7])
+	corrupt[7][size-1] ^= 1
+	ok, err = enc.VerifyAt(readersAt(corrupt), size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("verification did not fail")
+	}
+
+	// Reconstruct a data and a parity shard.
+	valid := make([][]byte, len(shards))
+	copy(valid, shards)
+	valid[2], valid[8] = nil, nil
+	fill := make([]io.WriterAt, len(shards))
+	filled := map[int]*writerAtBuffer{2: {}, 8: {}}
+	for i, w := range filled {
+		fill[i] = w
+	}
+	if err := enc.ReconstructAt(readersAt(valid), fill, size); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range filled {
+		if !bytes.Equal(w.b, shards[i]) {
+			t.Fatalf("reconstructed shard %d does not match", i)
+		}
+	}
+
+	// Errors
+	err = enc.EncodeAt(readersAt(data), writers, size+1)
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+	err = enc.EncodeAt(readersAt(data[:5]), writers, size)
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	valid[0], valid[1] = nil, nil
+	err = enc.ReconstructAt(readersAt(valid), fill, size)
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	fill[3] = &writerAtBuffer{}
+	err = enc.ReconstructAt(readersAt(shards), fill, size)
+	if err != ErrReconstructMismatch {
+		t.Errorf("expected %v, got %v", ErrReconstructMismatch, err)
+	}
+}
+
+func TestStreamCheckpoint(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	want := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(want)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, depth := range []int{0, 3} {
+		var cps []StreamCheckpoint
+		enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000), WithStreamPipelineDepth(depth), WithStreamCheckpoint(3, func(cp StreamCheckpoint) {
+			cps = append(cps, cp)
+		}))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode(toReaders(toBuffers(data)), toWriters(emptyBuffers(3))); err != nil {
+			t.Fatal(err)
+		}
+		wantCps := []StreamCheckpoint{{30000, 3}, {60000, 6}, {90000, 9}, {120000, 12}, {size, 13}}
+		if len(cps) != len(wantCps) {
+			t.Fatalf("depth %d: expected checkpoints %v, got %v", depth, wantCps, cps)
+		}
+		for i := range cps {
+			if cps[i] != wantCps[i] {
+				t.Fatalf("depth %d: expected checkpoints %v, got %v", depth, wantCps, cps)
+			}
+		}
+
+		// Fail during encoding and resume from the last checkpoint.
+		cps = nil
+		readers := toReaders(toBuffers(data))
+		readers[2] = &failAfter{b: data[2], n: 75000}
+		parity := make([]*writerAtBuffer, 3)
+		writers := make([]io.Writer, 3)
+		for i := range parity {
+			parity[i] = &writerAtBuffer{}
+			writers[i] = parity[i]
+		}
+		err = enc.Encode(readers, writers)
+		if se, ok := err.(StreamReadError); !ok || se.Err != errTestFail {
+			t.Fatalf("expected %T, got %v", StreamReadError{}, err)
+		}
+		if len(cps) == 0 {
+			t.Fatal("no checkpoints")
+		}
+		cp := cps[len(cps)-1]
+		if cp.Offset != 60000 {
+			t.Fatalf("depth %d: unexpected checkpoint %v", depth, cp)
+		}
+		cps = nil
+		seekers := make([]io.ReadSeeker, len(data))
+		for i := range data {
+			seekers[i] = bytes.NewReader(data[i])
+		}
+		writeSeekers := make([]io.WriteSeeker, len(parity))
+		for i := range parity {
+			writeSeekers[i] = parity[i]
+		}
+		if err := enc.EncodeFrom(seekers, writeSeekers, cp.Offset); err != nil {
+			t.Fatal(err)
+		}
+		for i := range parity {
+			if !bytes.Equal(parity[i].b, want[i].Bytes()) {
+				t.Fatalf("depth %d: parity shard %d does not match", depth, i)
+			}
+		}
+		if cps[0] != (StreamCheckpoint{90000, 9}) || cps[len(cps)-1] != (StreamCheckpoint{size, 13}) {
+			t.Fatalf("depth %d: unexpected checkpoints %v", depth, cps)
+		}
+
+		// Resuming at the end is a no-op.
+		if err := enc.EncodeFrom(seekers, writeSeekers, size); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStreamProgress(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	var progress []StreamProgress
+	var blocks []StreamBlock
+	hookErr := error(nil)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000),
+		WithStreamProgress(func(p StreamProgress) {
+			progress = append(progress, p)
+		}),
+		WithStreamBlockHook(func(b StreamBlock) error {
+			// Only keep the sizes.
+			shards := make([][]byte, len(b.Shards))
+			for i := range b.Shards {
+				shards[i] = make([]byte, len(b.Shards[i]))
+			}
+			b.Shards = shards
+			blocks = append(blocks, b)
+			return hookErr
+		}))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset := func() {
+		progress, blocks = nil, nil
+	}
+
+	parity := emptyBuffers(3)
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(parity)); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 13 || len(blocks) != 13 {
+		t.Fatalf("expected 13 blocks, got %d progress and %d blocks", len(progress), len(blocks))
+	}
+	last := progress[len(progress)-1]
+	if last.Blocks != 13 || last.Bytes != 6*size || len(last.ReadLatency) != 6 || len(last.WriteLatency) != 3 {
+		t.Fatalf("unexpected progress %+v", last)
+	}
+	for i, b := range blocks {
+		if b.Index != int64(i) || b.Offset != int64(i)*10000 || len(b.Shards) != 9 || len(b.Shards[8]) != min(10000, size-i*10000) {
+			t.Fatalf("unexpected block %d: index %d, offset %d", i, b.Index, b.Offset)
+		}
+	}
+	reset()
+
+	shards := append(data, toBytes(parity)...)
+	ok, err := enc.Verify(toReaders(toBuffers(shards)))
+	if err != nil || !ok {
+		t.Fatal("verification failed", err)
+	}
+	if len(progress) != 13 || len(blocks) != 13 || progress[12].Bytes != 9*size {
+		t.Fatalf("expected 13 blocks, got %d progress and %d blocks", len(progress), len(blocks))
+	}
+	reset()
+
+	valid := toReaders(toBuffers(shards))
+	valid[0] = nil
+	fill := nilWriters(9)
+	fill[0] = new(bytes.Buffer)
+	if err := enc.Reconstruct(valid, fill); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 13 || len(blocks) != 13 || len(blocks[0].Shards[0]) != 10000 {
+		t.Fatalf("expected 13 blocks, got %d progress and %d blocks", len(progress), len(blocks))
+	}
+	reset()
+
+	// Split and Join use a block per shard.
+	joined := bytes.Join(data, nil)
+	split := emptyBuffers(6)
+	if err := enc.Split(bytes.NewReader(joined), toWriters(split), int64(len(joined))); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 6*13 || len(blocks) != 6*13 || len(blocks[13].Shards[1]) != 10000 || blocks[13].Offset != 0 {
+		t.Fatalf("expected %d blocks, got %d progress and %d blocks", 6*13, len(progress), len(blocks))
+	}
+	reset()
+	splits := toBytes(split)
+	buf := new(bytes.Buffer)
+	if err := enc.Join(buf, toReaders(toBuffers(splits)), int64(len(joined))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), joined) {
+		t.Fatal("joined data does not match")
+	}
+	if len(progress) != 6*13 || progress[len(progress)-1].Bytes != int64(len(joined)) {
+		t.Fatalf("expected %d blocks, got %d", 6*13, len(progress))
+	}
+	err = enc.Join(buf, toReaders(toBuffers(splits)), int64(len(joined)+1))
+	if err != ErrShortData {
+		t.Errorf("expected %v, got %v", ErrShortData, err)
+	}
+
+	// The hook can stop the operation.
+	hookErr = errTestFail
+	err = enc.Encode(toReaders(toBuffers(data)), toWriters(emptyBuffers(3)))
+	if err != errTestFail {
+		t.Errorf("expected %v, got %v", errTestFail, err)
+	}
+}
+
+func TestStreamVerifyDetailed(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	parity := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(parity)); err != nil {
+		t.Fatal(err)
+	}
+	shards := append(data, toBytes(parity)...)
+	mismatches, err := enc.VerifyDetailed(toReaders(toBuffers(shards)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mismatches != nil {
+		t.Fatalf("unexpected mismatches %v", mismatches)
+	}
+
+	// Corrupt a data shard in block 3 and a parity shard in the last block.
+	shards[2] = bytes.Clone(shards[2])
+	shards[2][35000] ^= 1
+	shards[7] = bytes.Clone(shards[7])
+	shards[7][size-1] ^= 1
+	mismatches, err = enc.VerifyDetailed(toReaders(toBuffers(shards)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StreamMismatch{
+		{Offset: 30000, Length: 10000, Parity: []int{6, 7, 8}, Suspect: 2},
+		{Offset: 120000, Length: 3457, Parity: []int{7}, Suspect: 7},
+	}
+	if len(mismatches) != len(want) {
+		t.Fatalf("expected %v, got %v", want, mismatches)
+	}
+	for i := range want {
+		got := mismatches[i]
+		if got.Offset != want[i].Offset || got.Length != want[i].Length || got.Suspect != want[i].Suspect || !slices.Equal(got.Parity, want[i].Parity) {
+			t.Errorf("expected %v, got %v", want[i], got)
+		}
+	}
+
+	// With one parity shard the suspect is unknown.
+	enc, err = NewStream(8, 1, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err = enc.VerifyDetailed(toReaders(toBuffers(shards)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) == 0 || mismatches[0].Suspect != -1 {
+		t.Fatalf("unexpected mismatches %v", mismatches)
+	}
+
+	_, err = enc.VerifyDetailed(toReaders(emptyBuffers(9)))
+	if err != ErrShardNoData {
+		t.Errorf("expected %v, got %v", ErrShardNoData, err)
+	}
+	_, err = enc.VerifyDetailed(toReaders(emptyBuffers(3)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+}
+
+func TestStreamReconstructSome(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	parity := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(parity)); err != nil {
+		t.Fatal(err)
+	}
+	shards := append(data, toBytes(parity)...)
+
+	// Unused shards fail when read.
+	getReaders := func(missing ...int) []io.Reader {
+		r := toReaders(toBuffers(shards))
+		for _, i := range missing {
+			r[i] = nil
+		}
+		return r
+	}
+	failUnused := func(r []io.Reader, unused ...int) []io.Reader {
+		for _, i := range unused {
+			r[i] = &failAfter{b: shards[i]}
+		}
+		return r
+	}
+
+	// Reconstruct data shard 1 and 4, which should only read parity shard 6 and 7.
+	fill := emptyBuffers(9)
+	writers := nilWriters(9)
+	writers[1], writers[4], writers[8] = fill[1], fill[4], fill[8]
+	if err := enc.ReconstructData(failUnused(getReaders(1, 4, 8), 8), writers); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{1, 4} {
+		if !bytes.Equal(fill[i].Bytes(), shards[i]) {
+			t.Fatalf("shard %d does not match", i)
+		}
+	}
+	if fill[8].Len() != 0 {
+		t.Fatal("parity shard was written")
+	}
+
+	// Reconstruct parity shard 8 only from the data shards.
+	fill = emptyBuffers(9)
+	writers = nilWriters(9)
+	writers[8] = fill[8]
+	if err := enc.ReconstructSome(failUnused(getReaders(8), 6, 7), writers); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fill[8].Bytes(), shards[8]) {
+		t.Fatal("shard 8 does not match")
+	}
+
+	// Reconstruct data shard 0 and parity shard 6.
+	fill = emptyBuffers(9)
+	writers = nilWriters(9)
+	writers[0], writers[6] = fill[0], fill[6]
+	if err := enc.ReconstructSome(failUnused(getReaders(0, 6), 8), writers); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 6} {
+		if !bytes.Equal(fill[i].Bytes(), shards[i]) {
+			t.Fatalf("shard %d does not match", i)
+		}
+	}
+
+	err = enc.ReconstructSome(getReaders(0, 1, 2, 3), nilWriters(9))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+	writers = nilWriters(9)
+	writers[2] = new(bytes.Buffer)
+	err = enc.ReconstructData(getReaders(), writers)
+	if err != ErrReconstructMismatch {
+		t.Errorf("expected %v, got %v", ErrReconstructMismatch, err)
+	}
+}
+
+func TestStreamUpdate(t *testing.T) {
+	const size = 123457
+	data := randomBytes(6, size)
+	parity := emptyBuffers(3)
+	enc, err := NewStream(6, 3, testOptions(WithStreamBlockSize(10000))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(toReaders(toBuffers(data)), toWriters(parity)); err != nil {
+		t.Fatal(err)
+	}
+	oldParity := toBytes(parity)
+
+	newData := make([][]byte, len(data))
+	copy(newData, data)
+	newData[1] = randomBytes(1, size)[0]
+	newData[4] = randomBytes(1, size)[0]
+	want := emptyBuffers(3)
+	if err := enc.Encode(toReaders(toBuffers(newData)), toWriters(want)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged data shards are not read.
+	got := emptyBuffers(3)
+	err = enc.Update(
+		map[int]io.Reader{1: bytes.NewReader(data[1]), 4: bytes.NewReader(data[4])},
+		map[int]io.Reader{1: bytes.NewReader(newData[1]), 4: bytes.NewReader(newData[4])},
+		toReaders(toBuffers(oldParity)), toWriters(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		if !bytes.Equal(got[i].Bytes(), want[i].Bytes()) {
+			t.Fatalf("parity shard %d does not match", i)
+		}
+	}
+
+	err = enc.Update(
+		map[int]io.Reader{1: bytes.NewReader(data[1])},
+		map[int]io.Reader{1: &failAfter{b: newData[1], n: 20000}},
+		toReaders(toBuffers(oldParity)), toWriters(emptyBuffers(3)))
+	if se, ok := err.(StreamReadError); !ok || se.Err != errTestFail || se.Stream != 1 {
+		t.Errorf("expected %v, got %v", StreamReadError{Err: errTestFail, Stream: 1}, err)
+	}
+	err = enc.Update(
+		map[int]io.Reader{1: bytes.NewReader(data[1])},
+		map[int]io.Reader{2: bytes.NewReader(newData[1])},
+		toReaders(toBuffers(oldParity)), toWriters(emptyBuffers(3)))
+	if err != ErrInvalidInput {
+		t.Errorf("expected %v, got %v", ErrInvalidInput, err)
+	}
+	err = enc.Update(
+		map[int]io.Reader{6: bytes.NewReader(data[1])},
+		map[int]io.Reader{6: bytes.NewReader(newData[1])},
+		toReaders(toBuffers(oldParity)), toWriters(emptyBuffers(3)))
+	if err != ErrInvShardNum {
+		t.Errorf("expected %v, got %v", ErrInvShardNum, err)
+	}
+	err = enc.Update(nil, nil, toReaders(toBuffers(oldParity)), toWriters(emptyBuffers(2)))
+	if err != ErrTooFewShards {
+		t.Errorf("expected %v, got %v", ErrTooFewShards, err)
+	}
+}
+
 func TestNewStream(t *testing.T) {
 	tests := []struct {
 		data, parity int